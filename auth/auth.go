@@ -0,0 +1,160 @@
+// Package auth handles passwords, the JWTs issued at login and the HTTP
+// middleware that authenticates requests with them.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pgrzankowski/dictionary-app/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnauthenticated is wrapped by errors returned to callers that have
+	// not signed in.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden is wrapped by errors returned to users whose role does not
+	// allow what they asked for.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// The roles of models.User, from the least to the most privileged.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// IsRole reports whether role is one of the known roles.
+func IsRole(role string) bool {
+	return roleRanks[role] > 0
+}
+
+// HasRole reports whether user is granted required, which is the case for the
+// role itself and every more privileged one.
+func HasRole(user *models.User, required string) bool {
+	return user != nil && IsRole(required) && roleRanks[user.Role] >= roleRanks[required]
+}
+
+const DefaultTokenTTL = 24 * time.Hour
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func CheckPassword(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// Issuer signs and verifies HS256 tokens naming a user as their subject.
+type Issuer struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+func NewIssuer(secret []byte) *Issuer {
+	return &Issuer{Secret: secret, TTL: DefaultTokenTTL}
+}
+
+func (i *Issuer) Issue(userID uint) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(int(userID)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(i.TTL)),
+	})
+
+	signed, err := token.SignedString(i.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Parse verifies token and returns the id of the user it was issued to.
+func (i *Issuer) Parse(token string) (uint, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return i.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid subject", ErrUnauthenticated)
+	}
+	return uint(userID), nil
+}
+
+type contextKey struct{}
+
+// WithUser returns a copy of ctx carrying user as the authenticated user.
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user, or nil for anonymous
+// requests.
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(contextKey{}).(*models.User)
+	return user
+}
+
+// Middleware authenticates requests carrying an "Authorization: Bearer"
+// header and stores the user in the request context. Requests without the
+// header pass through anonymously, while invalid tokens are rejected.
+func Middleware(db *gorm.DB, issuer *Issuer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				http.Error(w, "expected a bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			userID, err := issuer.Parse(token)
+			if err != nil {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			var user models.User
+			if err := db.WithContext(r.Context()).First(&user, userID).Error; err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					log.Printf("failed to fetch user %d: %v", userID, err)
+				}
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), &user)))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassword(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse")
+	assert.NoError(t, err, "HashPassword should not return an error")
+	assert.NotEqual(t, "correct horse", hash, "Password should not be stored in plain text")
+	assert.True(t, auth.CheckPassword(hash, "correct horse"), "Correct password should match")
+	assert.False(t, auth.CheckPassword(hash, "wrong horse"), "Wrong password should not match")
+}
+
+func TestIssuer(t *testing.T) {
+	issuer := auth.NewIssuer([]byte("secret"))
+
+	token, err := issuer.Issue(42)
+	assert.NoError(t, err, "Issue should not return an error")
+
+	userID, err := issuer.Parse(token)
+	assert.NoError(t, err, "Parse should not return an error")
+	assert.Equal(t, uint(42), userID, "User id should match")
+
+	_, err = auth.NewIssuer([]byte("other secret")).Parse(token)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated, "Token signed with another secret should be rejected")
+
+	expired := &auth.Issuer{Secret: []byte("secret"), TTL: -time.Minute}
+	token, _ = expired.Issue(42)
+	_, err = issuer.Parse(token)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated, "Expired token should be rejected")
+}
+
+func TestMiddleware(t *testing.T) {
+	issuer := auth.NewIssuer([]byte("secret"))
+	var anonymous bool
+	handler := auth.Middleware(nil, issuer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anonymous = auth.UserFromContext(r.Context()) == nil
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/query", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code, "Requests without a token should pass")
+	assert.True(t, anonymous, "Requests without a token should be anonymous")
+
+	for _, header := range []string{"Basic dXNlcjpwYXNz", "Bearer invalid"} {
+		request := httptest.NewRequest(http.MethodPost, "/query", nil)
+		request.Header.Set("Authorization", header)
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Header %q should be rejected", header)
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	editor := &models.User{Role: auth.RoleEditor}
+
+	assert.True(t, auth.HasRole(editor, auth.RoleViewer), "Editor should be granted the viewer role")
+	assert.True(t, auth.HasRole(editor, auth.RoleEditor), "Editor should be granted the editor role")
+	assert.False(t, auth.HasRole(editor, auth.RoleAdmin), "Editor should not be granted the admin role")
+	assert.False(t, auth.HasRole(nil, auth.RoleViewer), "Anonymous callers should not be granted any role")
+	assert.False(t, auth.HasRole(&models.User{Role: "owner"}, auth.RoleViewer), "Unknown roles should not be granted anything")
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/config"
+	"github.com/pgrzankowski/dictionary-app/db"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/migrations"
+	"github.com/pgrzankowski/dictionary-app/services"
+)
+
+// runCommand runs the subcommand named by args[0] instead of the server.
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "import":
+		return runImport(cfg, args[1:])
+	case "export":
+		return runExport(cfg, args[1:])
+	case "migrate":
+		return runMigrate(cfg, args[1:])
+	case "set-role":
+		return runSetRole(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func runImport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatName := flags.String("format", "csv", "file format, csv or tsv")
+	dryRun := flags.Bool("dry-run", false, "validate the file without writing anything")
+	bestEffort := flags.Bool("best-effort", false, "write the valid rows even if some are invalid")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: server import [flags] FILE")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected a single file")
+	}
+
+	format := model.ImportFormat(strings.ToUpper(*formatName))
+	if !format.IsValid() {
+		return fmt.Errorf("unsupported format %q", *formatName)
+	}
+	mode := model.ImportModeAllOrNothing
+	if *bestEffort {
+		mode = model.ImportModeBestEffort
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	database, err := db.ConnectGORM(cfg.Database)
+	if err != nil {
+		return err
+	}
+	report, err := services.ImportTranslations(database, context.Background(), file, &format, dryRun, &mode)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		if row.Status != model.ImportRowStatusCreated {
+			fmt.Printf("line %d: %s: %s\n", row.Line, strings.ToLower(string(row.Status)), *row.Message)
+		}
+	}
+	fmt.Printf("%d created, %d duplicates, %d invalid\n", report.Created, report.Duplicates, report.Invalid)
+
+	switch {
+	case *dryRun:
+		fmt.Println("dry run, nothing was written")
+	case !report.Committed:
+		return fmt.Errorf("nothing was written because of invalid rows")
+	}
+
+	return nil
+}
+
+func runExport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatName := flags.String("format", string(services.ExportFormatJSONL), "file format, jsonl, csv or anki")
+	output := flags.String("o", "", "file to write to instead of standard output")
+	source := flags.String("source", "", "only export translations from this language")
+	target := flags.String("target", "", "only export translations into this language")
+	since := flags.String("since", "", "only export translations created at or after this date")
+	until := flags.String("until", "", "only export translations created before this date")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format := services.ExportFormat(*formatName)
+	if !format.IsValid() {
+		return fmt.Errorf("unsupported format %q", *formatName)
+	}
+	filter, err := exportFilter(*source, *target, *since, *until)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	database, err := db.ConnectGORM(cfg.Database)
+	if err != nil {
+		return err
+	}
+	return services.ExportTranslations(database, context.Background(), out, format, filter)
+}
+
+func runMigrate(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to undo with down")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: server migrate up|down|status [flags]")
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 {
+		flags.Usage()
+		return fmt.Errorf("expected an action")
+	}
+	action := args[0]
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if action != "up" && action != "down" && action != "status" {
+		flags.Usage()
+		return fmt.Errorf("unknown action %q", action)
+	}
+
+	database, err := db.OpenGORM(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrations.Up(database)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d %s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("already up to date")
+		}
+		return err
+	case "down":
+		undone, err := migrations.Down(database, *steps)
+		for _, migration := range undone {
+			fmt.Printf("undone %04d %s\n", migration.Version, migration.Name)
+		}
+		return err
+	default:
+		states, err := migrations.Status(database)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			status := "pending"
+			if state.AppliedAt != nil {
+				status = "applied " + state.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d %-24s %s\n", state.Version, state.Name, status)
+		}
+		return nil
+	}
+}
+
+// runSetRole changes the role of a registered user. Users register as
+// viewers, so this is how the first admin is appointed.
+func runSetRole(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("set-role", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: server set-role EMAIL viewer|editor|admin")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("expected an email and a role")
+	}
+
+	role := model.Role(strings.ToUpper(flags.Arg(1)))
+	if !role.IsValid() {
+		return fmt.Errorf("unknown role %q", flags.Arg(1))
+	}
+
+	database, err := db.ConnectGORM(cfg.Database)
+	if err != nil {
+		return err
+	}
+	user, err := services.SetUserRoleByEmail(database, context.Background(), flags.Arg(0), role)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is now %s\n", user.Email, strings.ToLower(string(user.Role)))
+	return nil
+}
@@ -0,0 +1,476 @@
+// Package config loads the settings of the server from, in increasing order of
+// precedence, the defaults, an optional YAML file, the environment and the
+// command line flags.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Database Database `yaml:"database"`
+	Auth     Auth     `yaml:"auth"`
+	Trash    Trash    `yaml:"trash"`
+	Tracing  Tracing  `yaml:"tracing"`
+
+	// PrintConfig is set by --print-config, asking for the configuration to
+	// be printed instead of starting the server.
+	PrintConfig bool `yaml:"-"`
+}
+
+type Server struct {
+	Port              string        `yaml:"port"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	// WriteTimeout is off by default, as exports are streamed for as long as
+	// they take.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+}
+
+// Database is where the data is stored. A URL takes precedence over the
+// separate connection settings, and parameters given in it over the SSL mode
+// and timeouts.
+type Database struct {
+	URL         string `yaml:"url"`
+	Host        string `yaml:"host"`
+	Port        string `yaml:"port"`
+	User        string `yaml:"user"`
+	Password    string `yaml:"password"`
+	Name        string `yaml:"name"`
+	SSLMode     string `yaml:"ssl_mode"`
+	SSLRootCert string `yaml:"ssl_root_cert"`
+	// Schema holds the tables when it is not public. public is searched
+	// after it, since the extensions used by search are installed there.
+	Schema string `yaml:"schema"`
+
+	MaxOpenConns     int           `yaml:"max_open_conns"`
+	MaxIdleConns     int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime  time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime  time.Duration `yaml:"conn_max_idle_time"`
+	ConnectTimeout   time.Duration `yaml:"connect_timeout"`
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+	// StartupTimeout is how long connecting is retried at startup, while the
+	// database may still be starting itself.
+	StartupTimeout time.Duration `yaml:"startup_timeout"`
+}
+
+type Auth struct {
+	JWTSecret string `yaml:"jwt_secret"`
+}
+
+type Trash struct {
+	// Retention is how long removed translations stay in the trash.
+	Retention time.Duration `yaml:"retention"`
+}
+
+// Tracing is where OpenTelemetry spans are sent.
+type Tracing struct {
+	// Exporter is one of none, otlp and stdout.
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the URL of the OTLP/HTTP collector. When it is empty, the
+	// standard OTEL_EXPORTER_OTLP_* variables apply.
+	Endpoint string `yaml:"endpoint"`
+	// SampleRatio is the share of new traces that are recorded. Traces
+	// continued from a traceparent header follow the sampling decision in it.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Default returns the configuration used for whatever is not set otherwise.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:              "8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       time.Minute,
+			IdleTimeout:       2 * time.Minute,
+		},
+		Database: defaultDatabase(),
+		Trash: Trash{
+			Retention: 30 * 24 * time.Hour,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+	}
+}
+
+func defaultDatabase() Database {
+	return Database{
+		Port:            "5432",
+		SSLMode:         "prefer",
+		MaxOpenConns:    20,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+		ConnectTimeout:  10 * time.Second,
+		StartupTimeout:  time.Minute,
+	}
+}
+
+// Load returns the configuration together with the arguments left after the
+// flags in args. The YAML file is the one given with --config, or else in
+// CONFIG_FILE. The result is not validated, so that --print-config can show
+// what is wrong with it.
+func Load(args []string) (*Config, []string, error) {
+	// The flags are parsed once to find the file, and again over the file and
+	// the environment so that they take precedence.
+	scratch := Default()
+	flags, file := newFlagSet(&scratch)
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	config := Default()
+	if *file != "" {
+		if err := config.loadFile(*file); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := config.loadEnv(); err != nil {
+		return nil, nil, err
+	}
+
+	flags, _ = newFlagSet(&config)
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	return &config, flags.Args(), nil
+}
+
+// TestDatabase returns the test database configured by the DB_TEST_*
+// variables or DATABASE_TEST_URL. Connecting to it is not retried unless
+// DB_TEST_STARTUP_TIMEOUT asks for it, so that tests without it skip quickly.
+func TestDatabase() (Database, error) {
+	database := defaultDatabase()
+	database.StartupTimeout = 0
+	if err := applyEnv(databaseSettings(&database, "DB_TEST_", "DATABASE_TEST_URL")); err != nil {
+		return database, err
+	}
+
+	return database, nil
+}
+
+// setting is a value that can be set from the environment, a flag or both.
+// target is a *string, *int, *float64, *bool or *time.Duration.
+type setting struct {
+	env    string
+	flag   string
+	usage  string
+	target interface{}
+}
+
+func (c *Config) settings() []setting {
+	return append([]setting{
+		{env: "PORT", flag: "port", usage: "port to listen on", target: &c.Server.Port},
+		{env: "HTTP_READ_HEADER_TIMEOUT", flag: "read-header-timeout", usage: "time allowed to read request headers", target: &c.Server.ReadHeaderTimeout},
+		{env: "HTTP_READ_TIMEOUT", flag: "read-timeout", usage: "time allowed to read a request", target: &c.Server.ReadTimeout},
+		{env: "HTTP_WRITE_TIMEOUT", flag: "write-timeout", usage: "time allowed to write a response, 0 for no limit", target: &c.Server.WriteTimeout},
+		{env: "HTTP_IDLE_TIMEOUT", flag: "idle-timeout", usage: "time to keep idle connections open", target: &c.Server.IdleTimeout},
+		{env: "JWT_SECRET", target: &c.Auth.JWTSecret},
+		{env: "TRASH_RETENTION", flag: "trash-retention", usage: "how long removed translations stay in the trash", target: &c.Trash.Retention},
+		{env: "TRACING_EXPORTER", flag: "tracing-exporter", usage: "where to send traces, one of " + strings.Join(tracingExporters, ", "), target: &c.Tracing.Exporter},
+		{env: "TRACING_ENDPOINT", flag: "tracing-endpoint", usage: "URL of the OTLP/HTTP collector", target: &c.Tracing.Endpoint},
+		{env: "TRACING_SAMPLE_RATIO", flag: "tracing-sample-ratio", usage: "share of new traces to record, from 0 to 1", target: &c.Tracing.SampleRatio},
+		{flag: "print-config", usage: "print the configuration and exit", target: &c.PrintConfig},
+	}, databaseSettings(&c.Database, "DB_", "DATABASE_URL")...)
+}
+
+// databaseSettings binds database to the environment variables starting with
+// prefix and the URL in urlEnv.
+func databaseSettings(database *Database, prefix string, urlEnv string) []setting {
+	return []setting{
+		{env: urlEnv, target: &database.URL},
+		{env: prefix + "HOST", flag: "db-host", usage: "database host", target: &database.Host},
+		{env: prefix + "PORT", flag: "db-port", usage: "database port", target: &database.Port},
+		{env: prefix + "USER", flag: "db-user", usage: "database user", target: &database.User},
+		{env: prefix + "PASS", target: &database.Password},
+		{env: prefix + "NAME", flag: "db-name", usage: "database name", target: &database.Name},
+		{env: prefix + "SSLMODE", flag: "db-sslmode", usage: "SSL mode, one of " + strings.Join(sslModes, ", "), target: &database.SSLMode},
+		{env: prefix + "SSLROOTCERT", flag: "db-sslrootcert", usage: "file with the certificates the server is verified against", target: &database.SSLRootCert},
+		{env: prefix + "SCHEMA", flag: "db-schema", usage: "schema holding the tables, public by default", target: &database.Schema},
+		{env: prefix + "MAX_OPEN_CONNS", flag: "db-max-open-conns", usage: "most open connections, 0 for no limit", target: &database.MaxOpenConns},
+		{env: prefix + "MAX_IDLE_CONNS", flag: "db-max-idle-conns", usage: "most idle connections kept open", target: &database.MaxIdleConns},
+		{env: prefix + "CONN_MAX_LIFETIME", flag: "db-conn-max-lifetime", usage: "time after which connections are closed, 0 for no limit", target: &database.ConnMaxLifetime},
+		{env: prefix + "CONN_MAX_IDLE_TIME", flag: "db-conn-max-idle-time", usage: "time after which idle connections are closed, 0 for no limit", target: &database.ConnMaxIdleTime},
+		{env: prefix + "CONNECT_TIMEOUT", flag: "db-connect-timeout", usage: "time allowed to connect to the database", target: &database.ConnectTimeout},
+		{env: prefix + "STATEMENT_TIMEOUT", flag: "db-statement-timeout", usage: "time allowed for a statement, 0 for no limit", target: &database.StatementTimeout},
+		{env: prefix + "STARTUP_TIMEOUT", flag: "db-startup-timeout", usage: "how long to retry connecting at startup, 0 to try once", target: &database.StartupTimeout},
+	}
+}
+
+// newFlagSet returns the flags setting config and the --config flag. Secrets
+// have no flags, since the command line is visible to other processes.
+func newFlagSet(config *Config) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: server [flags] [import|export|migrate ...]")
+		flags.PrintDefaults()
+	}
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML file to read the configuration from")
+
+	for _, s := range config.settings() {
+		if s.flag == "" {
+			continue
+		}
+		switch target := s.target.(type) {
+		case *string:
+			flags.StringVar(target, s.flag, *target, s.usage)
+		case *int:
+			flags.IntVar(target, s.flag, *target, s.usage)
+		case *float64:
+			flags.Float64Var(target, s.flag, *target, s.usage)
+		case *bool:
+			flags.BoolVar(target, s.flag, *target, s.usage)
+		case *time.Duration:
+			flags.DurationVar(target, s.flag, *target, s.usage)
+		}
+	}
+
+	return flags, file
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	return applyEnv(c.settings())
+}
+
+func applyEnv(settings []setting) error {
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(s.env)
+		if !ok || value == "" {
+			continue
+		}
+
+		var err error
+		switch target := s.target.(type) {
+		case *string:
+			*target = value
+		case *int:
+			*target, err = strconv.Atoi(value)
+		case *float64:
+			*target, err = strconv.ParseFloat(value, 64)
+		case *bool:
+			*target, err = strconv.ParseBool(value)
+		case *time.Duration:
+			*target, err = time.ParseDuration(value)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", s.env, value, err)
+		}
+	}
+
+	return nil
+}
+
+var tracingExporters = []string{"none", "otlp", "stdout"}
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Validate reports every missing or invalid setting the server needs.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Port == "" {
+		errs = append(errs, errors.New("port is required"))
+	} else if _, err := strconv.ParseUint(c.Server.Port, 10, 16); err != nil {
+		errs = append(errs, fmt.Errorf("invalid port %q", c.Server.Port))
+	}
+	for name, timeout := range map[string]time.Duration{
+		"read header timeout": c.Server.ReadHeaderTimeout,
+		"read timeout":        c.Server.ReadTimeout,
+		"write timeout":       c.Server.WriteTimeout,
+		"idle timeout":        c.Server.IdleTimeout,
+		"trash retention":     c.Trash.Retention,
+	} {
+		if timeout < 0 {
+			errs = append(errs, fmt.Errorf("%s cannot be negative", name))
+		}
+	}
+	if c.Auth.JWTSecret == "" {
+		errs = append(errs, errors.New("JWT secret is required"))
+	}
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if !slices.Contains(tracingExporters, c.Tracing.Exporter) {
+		errs = append(errs, fmt.Errorf("invalid tracing exporter %q, expected one of %s", c.Tracing.Exporter, strings.Join(tracingExporters, ", ")))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing sample ratio %v is not between 0 and 1", c.Tracing.SampleRatio))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every missing or invalid database setting.
+func (d Database) Validate() error {
+	var errs []error
+	if d.URL != "" {
+		if _, err := url.Parse(d.URL); err != nil {
+			errs = append(errs, errors.New("invalid database URL"))
+		}
+	} else {
+		if d.Host == "" {
+			errs = append(errs, errors.New("database host is required"))
+		}
+		if d.User == "" {
+			errs = append(errs, errors.New("database user is required"))
+		}
+		if d.Name == "" {
+			errs = append(errs, errors.New("database name is required"))
+		}
+	}
+
+	valid := false
+	for _, mode := range sslModes {
+		valid = valid || d.SSLMode == mode
+	}
+	if !valid {
+		errs = append(errs, fmt.Errorf("invalid SSL mode %q, expected one of %s", d.SSLMode, strings.Join(sslModes, ", ")))
+	}
+
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("connection limits cannot be negative"))
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("max idle connections (%d) cannot exceed max open connections (%d)", d.MaxIdleConns, d.MaxOpenConns))
+	}
+	if d.ConnMaxLifetime < 0 || d.ConnMaxIdleTime < 0 || d.ConnectTimeout < 0 || d.StatementTimeout < 0 || d.StartupTimeout < 0 {
+		errs = append(errs, errors.New("database timeouts cannot be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// DSN returns the connection string of the database.
+func (d Database) DSN() string {
+	params := map[string]string{"sslmode": d.SSLMode}
+	if d.SSLRootCert != "" {
+		params["sslrootcert"] = d.SSLRootCert
+	}
+	if d.ConnectTimeout > 0 {
+		// Whole seconds, rounded up since 0 would mean no timeout at all.
+		params["connect_timeout"] = strconv.Itoa(int(math.Ceil(d.ConnectTimeout.Seconds())))
+	}
+	if d.StatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(d.StatementTimeout.Milliseconds(), 10)
+	}
+	if d.Schema != "" {
+		params["search_path"] = d.Schema + ",public"
+	}
+
+	if d.URL != "" {
+		u, err := url.Parse(d.URL)
+		if err != nil {
+			return d.URL
+		}
+		query := u.Query()
+		for key, value := range params {
+			if !query.Has(key) {
+				query.Set(key, value)
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s",
+		quote(d.Host), quote(d.Port), quote(d.User), quote(d.Password), quote(d.Name))
+	for _, key := range []string{"sslmode", "sslrootcert", "connect_timeout", "statement_timeout", "search_path"} {
+		if value, ok := params[key]; ok {
+			dsn += fmt.Sprintf(" %s=%s", key, quote(value))
+		}
+	}
+
+	return dsn
+}
+
+// String returns the DSN without the password, so that it can be logged.
+func (d Database) String() string {
+	return d.redacted().DSN()
+}
+
+// quote escapes a value of a key/value connection string.
+func quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+const redacted = "xxxxx"
+
+func (d Database) redacted() Database {
+	if d.Password != "" {
+		d.Password = redacted
+	}
+	if d.URL != "" {
+		if u, err := url.Parse(d.URL); err == nil {
+			query := u.Query()
+			if query.Has("password") {
+				query.Set("password", redacted)
+				u.RawQuery = query.Encode()
+			}
+			d.URL = u.Redacted()
+		} else {
+			d.URL = redacted
+		}
+	}
+
+	return d
+}
+
+// Redacted returns a copy of the configuration with the secrets masked.
+func (c Config) Redacted() Config {
+	c.Database = c.Database.redacted()
+	if c.Auth.JWTSecret != "" {
+		c.Auth.JWTSecret = redacted
+	}
+
+	return c
+}
+
+// Print writes the configuration as YAML with the secrets masked.
+func (c Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
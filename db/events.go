@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/retry"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// EventsChannel is the Postgres channel on which instances share events.
+const EventsChannel = "dictionary_events"
+
+const (
+	// notifyBatchSize keeps payloads well below the 8000 byte limit of
+	// NOTIFY.
+	notifyBatchSize = 500
+)
+
+// listenRetry is how long apart the event listener reconnects.
+var listenRetry = retry.Policy{Initial: time.Second, Max: time.Minute}
+
+type notification struct {
+	Instance       string      `json:"instance"`
+	Kind           events.Kind `json:"kind"`
+	TranslationIDs []uint      `json:"translationIds"`
+}
+
+// Notify shares events of the given kind with the other instances using the
+// database, which receive them through ListenEvents.
+func Notify(db *gorm.DB, kind events.Kind, translationIDs ...uint) error {
+	for start := 0; start < len(translationIDs); start += notifyBatchSize {
+		end := min(start+notifyBatchSize, len(translationIDs))
+		payload, err := json.Marshal(notification{
+			Instance:       events.Instance,
+			Kind:           kind,
+			TranslationIDs: translationIDs[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode notification: %w", err)
+		}
+
+		if err := db.Exec("SELECT pg_notify(?, ?)", EventsChannel, string(payload)).Error; err != nil {
+			return fmt.Errorf("failed to notify: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListenEvents publishes the events notified by other instances to the local
+// subscribers until ctx is done. It keeps a connection of its own, which is
+// reopened with jittered exponential backoff when it is lost. Events notified while
+// the connection is down are missed.
+func ListenEvents(ctx context.Context, db *gorm.DB) {
+	dialector, ok := db.Dialector.(*postgres.Dialector)
+	if !ok {
+		log.Printf("event listener needs a postgres database")
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := listen(ctx, dialector.DSN, func() { attempt = 0 })
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := listenRetry.Delay(attempt)
+		log.Printf("event listener disconnected, reconnecting in %s: %v", delay.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// listen receives notifications until the connection fails, calling
+// connected once it listens.
+func listen(ctx context.Context, dsn string, connected func()) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+EventsChannel); err != nil {
+		return err
+	}
+	connected()
+
+	for {
+		received, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := rebroadcast(received.Payload); err != nil {
+			log.Printf("ignoring notification %q: %v", received.Payload, err)
+		}
+	}
+}
+
+// rebroadcast publishes the events of a notification sent by another
+// instance. The notifying instance has already published them itself.
+func rebroadcast(payload string) error {
+	var received notification
+	if err := json.Unmarshal([]byte(payload), &received); err != nil {
+		return err
+	}
+	if received.Instance == events.Instance {
+		return nil
+	}
+
+	for _, translationID := range received.TranslationIDs {
+		events.Publish(events.Event{Kind: received.Kind, TranslationID: translationID})
+	}
+
+	return nil
+}
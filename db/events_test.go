@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebroadcast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscriber := events.Subscribe(ctx)
+
+	err := rebroadcast(`{"instance":"` + events.Instance + `","kind":"created","translationIds":[1]}`)
+	assert.NoError(t, err, "rebroadcast should not return an error")
+	err = rebroadcast(`{"instance":"other","kind":"removed","translationIds":[2,3]}`)
+	assert.NoError(t, err, "rebroadcast should not return an error")
+	err = rebroadcast(`not json`)
+	assert.Error(t, err, "Malformed payloads should be rejected")
+
+	for _, expected := range []events.Event{
+		{Kind: events.TranslationRemoved, TranslationID: 2},
+		{Kind: events.TranslationRemoved, TranslationID: 3},
+	} {
+		select {
+		case event := <-subscriber:
+			assert.Equal(t, expected, event, "Events of other instances should be published")
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for an event")
+		}
+	}
+
+	select {
+	case event := <-subscriber:
+		t.Errorf("unexpected event %+v, events of this instance are already published", event)
+	default:
+	}
+}
+
+func TestListenEvents(t *testing.T) {
+	testDB, err := ConnectTestGORM("db_test")
+	if err != nil {
+		t.Skipf("test database unavailable: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscriber := events.Subscribe(ctx)
+	go ListenEvents(ctx, testDB)
+
+	// The listener connects in the background, so the notification is repeated
+	// until it arrives.
+	payload := `{"instance":"other","kind":"updated","translationIds":[7]}`
+	deadline := time.After(5 * time.Second)
+	for {
+		err := testDB.Exec("SELECT pg_notify(?, ?)", EventsChannel, payload).Error
+		assert.NoError(t, err, "pg_notify should not return an error")
+
+		select {
+		case event := <-subscriber:
+			assert.Equal(t, events.Event{Kind: events.TranslationUpdated, TranslationID: 7}, event,
+				"Notified events should be published")
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("timed out waiting for the notification")
+		}
+	}
+}
@@ -1,75 +1,141 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"os"
+	"strings"
+	"time"
 
-	"github.com/pgrzankowski/dictionary-app/models"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pgrzankowski/dictionary-app/config"
+	"github.com/pgrzankowski/dictionary-app/migrations"
+	"github.com/pgrzankowski/dictionary-app/retry"
+	"github.com/pgrzankowski/dictionary-app/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var GormDB *gorm.DB
-var GormTestDB *gorm.DB
-
-func ConnectGORM() {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASS")
-	dbname := os.Getenv("DB_NAME")
-
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname,
-	)
-	var err error
-	GormDB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// ConnectGORM connects to database and applies the pending migrations.
+func ConnectGORM(database config.Database) (*gorm.DB, error) {
+	db, err := OpenGORM(database)
 	if err != nil {
-		log.Fatalf("Could not connect to GORM database: %v", err)
+		return nil, err
 	}
 
-	if err := GormDB.AutoMigrate(&models.PolishWord{}); err != nil {
-		log.Fatalf("AutoMigrate PolishWord failed: %v", err)
+	return db, migrate(db)
+}
+
+// startupRetry is how long apart connecting is attempted while the database
+// is not up yet.
+var startupRetry = retry.Policy{Initial: 500 * time.Millisecond, Max: 10 * time.Second}
+
+// OpenGORM connects to database and leaves its schema as it is. Statements
+// run through the returned DB are traced. Connecting is retried for up to the
+// startup timeout of database, unless the database rejects the credentials or
+// does not exist.
+func OpenGORM(database config.Database) (*gorm.DB, error) {
+	if err := database.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
 	}
-	if err := GormDB.AutoMigrate(&models.Translation{}); err != nil {
-		log.Fatalf("AutoMigrate Translation failed: %v", err)
+
+	policy := startupRetry
+	if database.StartupTimeout == 0 {
+		policy.Attempts = 1
 	}
-	if err := GormDB.AutoMigrate(&models.Example{}); err != nil {
-		log.Fatalf("AutoMigrate Example failed: %v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), database.StartupTimeout)
+	defer cancel()
+
+	var db *gorm.DB
+	err := retry.Do(ctx, policy, isTransientConnectError, func(err error, delay time.Duration) {
+		log.Printf("Database is not available, retrying in %s: %v", delay.Round(time.Millisecond), err)
+	}, func() error {
+		var err error
+		db, err = gorm.Open(postgres.Open(database.DSN()), &gorm.Config{})
+		if err != nil && db != nil {
+			// gorm.Open leaves the pool open when the first ping fails.
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to GORM database: %w", err)
 	}
 
-	log.Printf("Connected to database using GORM: %s", dsn)
-}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(database.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(database.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(database.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(database.ConnMaxIdleTime)
 
-func ConnectTestGORM() {
-	host := os.Getenv("DB_TEST_HOST")
-	port := os.Getenv("DB_TEST_PORT")
-	user := os.Getenv("DB_TEST_USER")
-	password := os.Getenv("DB_TEST_PASS")
-	dbname := os.Getenv("DB_TEST_NAME")
+	if err := db.Use(tracing.GORMPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to trace GORM: %w", err)
+	}
 
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname,
-	)
+	log.Printf("Connected to database using GORM: %s", database)
+	return db, nil
+}
 
-	var err error
-	GormTestDB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// ConnectTestGORM connects to the test database configured by the DB_TEST_*
+// environment variables and applies the pending migrations in schema, which
+// is created if missing. Every package testing against the database uses a
+// schema of its own, so that go test can run the packages in parallel without
+// them clearing each other's tables.
+func ConnectTestGORM(schema string) (*gorm.DB, error) {
+	database, err := config.TestDatabase()
 	if err != nil {
-		log.Fatalf("Could not connect to GORM database: %v", err)
+		return nil, err
 	}
 
-	if err := GormTestDB.AutoMigrate(&models.PolishWord{}); err != nil {
-		log.Fatalf("AutoMigrate PolishWord failed: %v", err)
+	// Migrating public installs the extensions used by search there, where
+	// the migrations of every other schema find them.
+	public, err := ConnectGORM(database)
+	if err != nil {
+		return nil, err
+	}
+	err = public.Exec(`CREATE SCHEMA IF NOT EXISTS "` + schema + `"`).Error
+	if sqlDB, dbErr := public.DB(); dbErr == nil {
+		sqlDB.Close()
 	}
-	if err := GormTestDB.AutoMigrate(&models.Translation{}); err != nil {
-		log.Fatalf("AutoMigrate Translation failed: %v", err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+
+	database.Schema = schema
+	return ConnectGORM(database)
+}
+
+// isTransientConnectError tells whether connecting failed for a reason that
+// may go away while the database starts, rather than because the credentials
+// or the database name are wrong.
+func isTransientConnectError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return !strings.HasPrefix(pgErr.Code, invalidAuthorizationClass) && pgErr.Code != invalidCatalogNameCode
+	}
+
+	return true
+}
+
+const (
+	invalidAuthorizationClass = "28"
+	invalidCatalogNameCode    = "3D000"
+)
+
+func migrate(db *gorm.DB) error {
+	applied, err := migrations.Up(db)
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
 	}
-	if err := GormTestDB.AutoMigrate(&models.Example{}); err != nil {
-		log.Fatalf("AutoMigrate Example failed: %v", err)
+	for _, migration := range applied {
+		log.Printf("Applied migration %d %s", migration.Version, migration.Name)
 	}
 
-	log.Printf("Connected to database using GORM: %s", dsn)
+	return nil
 }
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pgrzankowski/dictionary-app/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// closedPort returns a local port nothing listens on.
+func closedPort(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	return strconv.Itoa(port)
+}
+
+func TestOpenGORMRetries(t *testing.T) {
+	database := config.Default().Database
+	database.Host = "127.0.0.1"
+	database.Port = closedPort(t)
+	database.User = "postgres"
+	database.Name = "dictionary"
+	database.SSLMode = "disable"
+	database.ConnectTimeout = time.Second
+
+	database.StartupTimeout = 0
+	start := time.Now()
+	_, err := OpenGORM(database)
+	assert.Error(t, err, "Connecting to a closed port should fail")
+	assert.Less(t, time.Since(start), startupRetry.Initial, "Connecting should not be retried without a startup timeout")
+
+	database.StartupTimeout = 300 * time.Millisecond
+	start = time.Now()
+	_, err = OpenGORM(database)
+	assert.Error(t, err, "Connecting to a closed port should fail")
+	assert.GreaterOrEqual(t, time.Since(start), database.StartupTimeout, "Connecting should be retried until the startup timeout")
+}
+
+func TestIsTransientConnectError(t *testing.T) {
+	assert.True(t, isTransientConnectError(errors.New("connection refused")), "Network errors should be transient")
+	assert.True(t, isTransientConnectError(&pgconn.PgError{Code: "57P03"}), "Database starting up should be transient")
+	assert.False(t, isTransientConnectError(&pgconn.PgError{Code: "28P01"}), "Wrong password should not be transient")
+	assert.False(t, isTransientConnectError(&pgconn.PgError{Code: "3D000"}), "Missing database should not be transient")
+}
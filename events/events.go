@@ -0,0 +1,98 @@
+// Package events is an in-process publish/subscribe hub for changes to the
+// dictionary, which the services publish to once their changes are committed.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// Kind tells what happened to a translation.
+type Kind string
+
+const (
+	TranslationCreated Kind = "created"
+	TranslationUpdated Kind = "updated"
+	TranslationRemoved Kind = "removed"
+)
+
+// Event only identifies the changed translation, so that it stays small and
+// subscribers load what they need.
+type Event struct {
+	Kind          Kind `json:"kind"`
+	TranslationID uint `json:"translationId"`
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// further events are dropped for it.
+const subscriberBuffer = 64
+
+// Broker delivers every published event to all current subscribers. Publish
+// never blocks, so a slow subscriber misses events instead of holding up the
+// publisher.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan Event]struct{})}
+}
+
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events published from now on. The
+// channel is closed once ctx is done.
+func (b *Broker) Subscribe(ctx context.Context) <-chan Event {
+	subscriber := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subscribers, subscriber)
+		b.mu.Unlock()
+		close(subscriber)
+	}()
+
+	return subscriber
+}
+
+// Instance identifies this process among the instances sharing events
+// through the database.
+var Instance = newInstanceID()
+
+func newInstanceID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Default is the broker the services publish to.
+var Default = NewBroker()
+
+// Publish publishes event to the Default broker.
+func Publish(event Event) {
+	Default.Publish(event)
+}
+
+// Subscribe subscribes to the Default broker.
+func Subscribe(ctx context.Context) <-chan Event {
+	return Default.Subscribe(ctx)
+}
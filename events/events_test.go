@@ -0,0 +1,67 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, subscriber <-chan events.Event) (events.Event, bool) {
+	select {
+	case event, ok := <-subscriber:
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an event")
+		return events.Event{}, false
+	}
+}
+
+func TestBroker(t *testing.T) {
+	broker := events.NewBroker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := broker.Subscribe(ctx)
+	second := broker.Subscribe(context.Background())
+
+	created := events.Event{Kind: events.TranslationCreated, TranslationID: 1}
+	broker.Publish(created)
+
+	event, _ := receive(t, first)
+	assert.Equal(t, created, event, "Every subscriber should receive the event")
+	event, _ = receive(t, second)
+	assert.Equal(t, created, event, "Every subscriber should receive the event")
+
+	cancel()
+	_, ok := receive(t, first)
+	assert.False(t, ok, "Channel should be closed once the context is done")
+
+	removed := events.Event{Kind: events.TranslationRemoved, TranslationID: 1}
+	broker.Publish(removed)
+	event, _ = receive(t, second)
+	assert.Equal(t, removed, event, "Remaining subscribers should still receive events")
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	broker := events.NewBroker()
+	subscriber := broker.Subscribe(context.Background())
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			broker.Publish(events.Event{Kind: events.TranslationUpdated, TranslationID: uint(i)})
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish should not block on a subscriber that does not read")
+	}
+
+	event, _ := receive(t, subscriber)
+	assert.Equal(t, uint(0), event.TranslationID, "Buffered events should be delivered in order")
+}
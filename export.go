@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/services"
+	"gorm.io/gorm"
+)
+
+// exportHandler streams the dictionary in database as a file download. The
+// query parameters match the flags of the export command: format, source,
+// target, since and until.
+func exportHandler(database *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		format := services.ExportFormatJSONL
+		if query.Has("format") {
+			format = services.ExportFormat(query.Get("format"))
+		}
+		if !format.IsValid() {
+			http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+			return
+		}
+
+		filter, err := exportFilter(query.Get("source"), query.Get("target"), query.Get("since"), query.Get("until"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="dictionary.%s"`, format.Extension()))
+
+		// Once streaming has started the status can no longer be changed, so
+		// failures only end the response early.
+		if err := services.ExportTranslations(database, r.Context(), w, format, filter); err != nil {
+			log.Printf("export failed: %v", err)
+		}
+	}
+}
+
+func exportFilter(source string, target string, since string, until string) (services.ExportFilter, error) {
+	filter := services.ExportFilter{SourceLanguage: source, TargetLanguage: target}
+
+	var err error
+	if filter.CreatedAfter, err = parseExportDate(since); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.CreatedBefore, err = parseExportDate(until); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+
+	return filter, nil
+}
+
+// parseExportDate accepts either a date or an RFC 3339 timestamp.
+func parseExportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+)
+
+// HasRole implements the @hasRole directive, letting only users granted role
+// resolve the field.
+func HasRole(ctx context.Context, obj interface{}, next graphql.Resolver, role model.Role) (interface{}, error) {
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return nil, fmt.Errorf("%w: sign in first", auth.ErrUnauthenticated)
+	}
+
+	if !auth.HasRole(user, strings.ToLower(role.String())) {
+		return nil, fmt.Errorf("%w: requires the %s role", auth.ErrForbidden, strings.ToLower(role.String()))
+	}
+
+	return next(ctx)
+}
+
+// currentUserID returns the id of the signed in user.
+func currentUserID(ctx context.Context) (uint, error) {
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return 0, fmt.Errorf("%w: sign in first", auth.ErrUnauthenticated)
+	}
+	return user.ID, nil
+}
+
+// optionalUserID returns the id of the signed in user, or 0, which no user has,
+// for anonymous requests.
+func optionalUserID(ctx context.Context) uint {
+	if user := auth.UserFromContext(ctx); user != nil {
+		return user.ID
+	}
+	return 0
+}
+
+func (r *Resolver) authPayload(user *model.User) (*model.AuthPayload, error) {
+	userID, err := strconv.Atoi(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := r.Tokens.Issue(uint(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthPayload{Token: token, User: user}, nil
+}
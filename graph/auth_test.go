@@ -0,0 +1,164 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/models"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// mutationRoles is the least privileged role allowed to call each mutation,
+// empty for the ones open to anonymous callers.
+var mutationRoles = map[string]string{
+	"register":           "",
+	"login":              "",
+	"addLanguage":        auth.RoleEditor,
+	"addTranslation":     auth.RoleEditor,
+	"createTranslation":  auth.RoleEditor,
+	"removeTranslation":  auth.RoleAdmin,
+	"importTranslations": auth.RoleEditor,
+	"updateTranslation":  auth.RoleEditor,
+	"revertTranslation":  auth.RoleEditor,
+	"restoreTranslation": auth.RoleEditor,
+	"renameLexeme":       auth.RoleEditor,
+	"deleteLexeme":       auth.RoleAdmin,
+	"renamePolishWord":   auth.RoleEditor,
+	"deletePolishWord":   auth.RoleAdmin,
+	"addExample":         auth.RoleEditor,
+	"updateExample":      auth.RoleEditor,
+	"removeExample":      auth.RoleAdmin,
+	"reorderExamples":    auth.RoleEditor,
+	"submitReview":       auth.RoleViewer,
+	"setUserRole":        auth.RoleAdmin,
+	"createWordList":     auth.RoleViewer,
+	"updateWordList":     auth.RoleViewer,
+	"deleteWordList":     auth.RoleViewer,
+	"addToWordList":      auth.RoleViewer,
+	"removeFromWordList": auth.RoleViewer,
+	"reorderWordList":    auth.RoleViewer,
+}
+
+// callers are ordered from the least to the most privileged.
+var callers = []struct {
+	name string
+	user *models.User
+}{
+	{"anonymous", nil},
+	{auth.RoleViewer, &models.User{ID: 1, Role: auth.RoleViewer}},
+	{auth.RoleEditor, &models.User{ID: 2, Role: auth.RoleEditor}},
+	{auth.RoleAdmin, &models.User{ID: 3, Role: auth.RoleAdmin}},
+}
+
+func newTestClient() *client.Client {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  &graph.Resolver{},
+		Directives: graph.DirectiveRoot{HasRole: graph.HasRole},
+	}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+
+	return client.New(srv)
+}
+
+func asUser(user *models.User) client.Option {
+	return func(request *client.Request) {
+		request.HTTP = request.HTTP.WithContext(auth.WithUser(request.HTTP.Context(), user))
+	}
+}
+
+func TestMutationRoles(t *testing.T) {
+	schema := graph.NewExecutableSchema(graph.Config{}).Schema()
+
+	for _, field := range schema.Mutation.Fields {
+		required, ok := mutationRoles[field.Name]
+		if !assert.True(t, ok, "Mutation %s should be listed in mutationRoles", field.Name) {
+			continue
+		}
+
+		directive := field.Directives.ForName("hasRole")
+		if required == "" {
+			assert.Nil(t, directive, "Mutation %s should be open to anonymous callers", field.Name)
+			continue
+		}
+		if !assert.NotNil(t, directive, "Mutation %s should have a @hasRole directive", field.Name) {
+			continue
+		}
+		role := model.Role(directive.Arguments.ForName("role").Value.Raw)
+
+		allowed := false
+		for _, caller := range callers {
+			allowed = allowed || caller.name == required
+
+			ctx := context.Background()
+			if caller.user != nil {
+				ctx = auth.WithUser(ctx, caller.user)
+			}
+			_, err := graph.HasRole(ctx, nil, func(context.Context) (interface{}, error) {
+				return true, nil
+			}, role)
+
+			switch {
+			case allowed:
+				assert.NoError(t, err, "%s should be allowed to call %s", caller.name, field.Name)
+			case caller.user == nil:
+				assert.ErrorIs(t, err, auth.ErrUnauthenticated, "%s should not be allowed to call %s", caller.name, field.Name)
+			default:
+				assert.ErrorIs(t, err, auth.ErrForbidden, "%s should not be allowed to call %s", caller.name, field.Name)
+			}
+		}
+	}
+}
+
+func TestHasRoleErrors(t *testing.T) {
+	c := newTestClient()
+
+	var response struct{ RemoveTranslation bool }
+	err := c.Post(`mutation { removeTranslation(id: "1") }`, &response)
+	assert.Error(t, err, "Anonymous mutation should be rejected")
+	assert.Contains(t, err.Error(), `"code":"UNAUTHENTICATED"`, "Error should carry a code")
+	assert.Contains(t, err.Error(), `"path":["removeTranslation"]`, "Error should point at the mutation")
+
+	err = c.Post(`mutation { removeTranslation(id: "1") }`, &response, asUser(callers[2].user))
+	assert.Error(t, err, "Editor should not delete translations")
+	assert.Contains(t, err.Error(), `"code":"FORBIDDEN"`, "Error should carry a code")
+	assert.Contains(t, err.Error(), `"path":["removeTranslation"]`, "Error should point at the mutation")
+}
+
+// updateService accepts every translation update.
+type updateService struct {
+	services.Service
+}
+
+func (updateService) UpdateTranslation(ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error) {
+	return &model.Translation{ID: input.ID}, nil
+}
+
+func TestUpdateTranslationRemoveExamples(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  &graph.Resolver{Service: updateService{}},
+		Directives: graph.DirectiveRoot{HasRole: graph.HasRole},
+	}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+	c := client.New(srv)
+
+	var response struct{ UpdateTranslation struct{ ID string } }
+	err := c.Post(`mutation { updateTranslation(input: {id: "1", addExamples: [{sentence: "Zamek stoi na wzgórzu."}]}) { id } }`, &response, asUser(callers[2].user))
+	assert.NoError(t, err, "Editor should add examples")
+
+	err = c.Post(`mutation { updateTranslation(input: {id: "1", removeExampleIds: ["1"]}) { id } }`, &response, asUser(callers[2].user))
+	assert.Error(t, err, "Editor should not remove examples")
+	assert.Contains(t, err.Error(), `"code":"FORBIDDEN"`, "Error should carry a code")
+
+	err = c.Post(`mutation { updateTranslation(input: {id: "1", removeExampleIds: ["1"]}) { id } }`, &response, asUser(callers[3].user))
+	assert.NoError(t, err, "Admin should remove examples")
+	assert.Equal(t, "1", response.UpdateTranslation.ID, "Translation should be returned")
+}
@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// errorCodes are the machine readable codes of the errors clients are
+// expected to handle.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{services.ErrConflict, "CONFLICT"},
+	{auth.ErrUnauthenticated, "UNAUTHENTICATED"},
+	{auth.ErrForbidden, "FORBIDDEN"},
+}
+
+// ErrorPresenter adds a machine readable code to the errors clients are
+// expected to handle.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+
+	for _, errorCode := range errorCodes {
+		if errors.Is(err, errorCode.err) {
+			if presented.Extensions == nil {
+				presented.Extensions = map[string]interface{}{}
+			}
+			presented.Extensions["code"] = errorCode.code
+			break
+		}
+	}
+
+	return presented
+}
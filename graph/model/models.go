@@ -0,0 +1,39 @@
+package model
+
+// The types below are bound in gqlgen.yml instead of being generated, so that
+// they only hold the keys of their relations. The relations themselves are
+// field resolvers backed by the per-request dataloaders.
+
+type Lexeme struct {
+	ID           string `json:"id"`
+	Word         string `json:"word"`
+	CreatedAt    string `json:"createdAt"`
+	UpdatedAt    string `json:"updatedAt"`
+	LanguageCode string `json:"-"`
+}
+
+type PolishWord struct {
+	ID        string `json:"id"`
+	Word      string `json:"word"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type Translation struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	// When the translation was moved to the trash, null unless it is in the trash.
+	DeletedAt      *string `json:"deletedAt,omitempty"`
+	SourceLexemeID uint    `json:"-"`
+	TargetLexemeID uint    `json:"-"`
+}
+
+type Example struct {
+	ID            string `json:"id"`
+	Sentence      string `json:"sentence"`
+	Position      int32  `json:"position"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+	TranslationID uint   `json:"-"`
+}
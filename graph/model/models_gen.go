@@ -2,14 +2,64 @@
 
 package model
 
-type Example struct {
-	ID          string       `json:"id"`
-	Sentence    string       `json:"sentence"`
-	CreatedAt   string       `json:"createdAt"`
-	UpdatedAt   string       `json:"updatedAt"`
-	Translation *Translation `json:"translation"`
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type AuthPayload struct {
+	// A bearer token for the Authorization header.
+	Token string `json:"token"`
+	User  *User  `json:"user"`
+}
+
+type ImportReport struct {
+	Created    int32 `json:"created"`
+	Duplicates int32 `json:"duplicates"`
+	Invalid    int32 `json:"invalid"`
+	// Whether the created rows were written, which is never the case for a dry run.
+	Committed bool         `json:"committed"`
+	Rows      []*ImportRow `json:"rows"`
 }
 
+type ImportRow struct {
+	Line        int32           `json:"line"`
+	Status      ImportRowStatus `json:"status"`
+	PolishWord  *string         `json:"polishWord,omitempty"`
+	EnglishWord *string         `json:"englishWord,omitempty"`
+	Message     *string         `json:"message,omitempty"`
+}
+
+type Language struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type LexemeConnection struct {
+	Edges    []*LexemeEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type LexemeEdge struct {
+	Cursor string  `json:"cursor"`
+	Node   *Lexeme `json:"node"`
+}
+
+type LexemeFilter struct {
+	Language        *string `json:"language,omitempty"`
+	Prefix          *string `json:"prefix,omitempty"`
+	Contains        *string `json:"contains,omitempty"`
+	HasTranslations *bool   `json:"hasTranslations,omitempty"`
+}
+
+type LexemeInput struct {
+	Language string `json:"language"`
+	Word     string `json:"word"`
+}
+
+// Every mutation except register and login requires a signed in user with the
+// role given by its @hasRole directive.
 type Mutation struct {
 }
 
@@ -23,27 +73,452 @@ type NewTranslationInput struct {
 	Examples    []*NewExampleInput `json:"examples,omitempty"`
 }
 
-type PolishWord struct {
-	ID           string         `json:"id"`
-	Word         string         `json:"word"`
-	CreatedAt    string         `json:"createdAt"`
-	UpdatedAt    string         `json:"updatedAt"`
-	Translations []*Translation `json:"translations"`
+type NewWordListInput struct {
+	Name           string              `json:"name"`
+	Visibility     *WordListVisibility `json:"visibility,omitempty"`
+	TranslationIds []string            `json:"translationIds,omitempty"`
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
+type PageInput struct {
+	First  *int32  `json:"first,omitempty"`
+	After  *string `json:"after,omitempty"`
+	Last   *int32  `json:"last,omitempty"`
+	Before *string `json:"before,omitempty"`
+}
+
+type PolishWordConnection struct {
+	Edges    []*PolishWordEdge `json:"edges"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+}
+
+type PolishWordEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *PolishWord `json:"node"`
+}
+
+type PolishWordFilter struct {
+	Prefix          *string `json:"prefix,omitempty"`
+	Contains        *string `json:"contains,omitempty"`
+	HasTranslations *bool   `json:"hasTranslations,omitempty"`
 }
 
 type Query struct {
 }
 
-type Translation struct {
-	ID          string      `json:"id"`
-	EnglishWord string      `json:"englishWord"`
-	CreatedAt   string      `json:"createdAt"`
-	UpdatedAt   string      `json:"updatedAt"`
-	PolishWord  *PolishWord `json:"polishWord"`
-	Examples    []*Example  `json:"examples"`
+// The spaced repetition progress on a translation. Translations that were never
+// reviewed are due right away with the initial ease factor.
+type Review struct {
+	Translation *Translation `json:"translation"`
+	EaseFactor  float64      `json:"easeFactor"`
+	// Days between the last review and the next one.
+	Interval       int32   `json:"interval"`
+	Repetitions    int32   `json:"repetitions"`
+	DueAt          string  `json:"dueAt"`
+	LastReviewedAt *string `json:"lastReviewedAt,omitempty"`
+}
+
+type SearchResult struct {
+	Translation *Translation `json:"translation"`
+	Score       float64      `json:"score"`
+}
+
+// Changes to the dictionary as they are committed. Each subscription can be
+// limited to the translations from or into a Polish word.
+type Subscription struct {
+}
+
+type TranslationConnection struct {
+	Edges    []*TranslationEdge `json:"edges"`
+	PageInfo *PageInfo          `json:"pageInfo"`
+}
+
+type TranslationEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *Translation `json:"node"`
+}
+
+// The translations of a single headword, as returned by the lookup queries.
+type TranslationGroup struct {
+	Headword     *Lexeme        `json:"headword"`
+	Translations []*Translation `json:"translations"`
+}
+
+type TranslationInput struct {
+	Source   *LexemeInput       `json:"source"`
+	Target   *LexemeInput       `json:"target"`
+	Examples []*NewExampleInput `json:"examples,omitempty"`
+}
+
+type TranslationRevision struct {
+	ID     string         `json:"id"`
+	Action RevisionAction `json:"action"`
+	// The user who made the change, null for changes made outside of the API.
+	Actor     *UserProfile `json:"actor,omitempty"`
+	CreatedAt string       `json:"createdAt"`
+	// JSON snapshot of the translation before the change, null when it was created.
+	Before *string `json:"before,omitempty"`
+	// JSON snapshot of the translation after the change, null when it was removed.
+	After *string `json:"after,omitempty"`
 }
 
 type UpdateTranslationInput struct {
-	ID          string  `json:"id"`
-	EnglishWord *string `json:"englishWord,omitempty"`
+	ID          string             `json:"id"`
+	Source      *LexemeInput       `json:"source,omitempty"`
+	Target      *LexemeInput       `json:"target,omitempty"`
+	EnglishWord *string            `json:"englishWord,omitempty"`
+	PolishWord  *string            `json:"polishWord,omitempty"`
+	AddExamples []*NewExampleInput `json:"addExamples,omitempty"`
+	// Removing examples requires the ADMIN role, like removeExample.
+	RemoveExampleIds []string `json:"removeExampleIds,omitempty"`
+}
+
+type UpdateWordListInput struct {
+	ID         string              `json:"id"`
+	Name       *string             `json:"name,omitempty"`
+	Visibility *WordListVisibility `json:"visibility,omitempty"`
+}
+
+type User struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Role      Role   `json:"role"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// What anyone can see of a user, which leaves out the email.
+type UserProfile struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// A user's named selection of translations from the shared dictionary.
+type WordList struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Visibility WordListVisibility `json:"visibility"`
+	Owner      *UserProfile       `json:"owner"`
+	CreatedAt  string             `json:"createdAt"`
+	UpdatedAt  string             `json:"updatedAt"`
+	Items      []*WordListItem    `json:"items"`
+}
+
+type WordListItem struct {
+	ID          string       `json:"id"`
+	Position    int32        `json:"position"`
+	AddedAt     string       `json:"addedAt"`
+	Translation *Translation `json:"translation"`
+}
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV ImportFormat = "CSV"
+	ImportFormatTsv ImportFormat = "TSV"
+)
+
+var AllImportFormat = []ImportFormat{
+	ImportFormatCSV,
+	ImportFormatTsv,
+}
+
+func (e ImportFormat) IsValid() bool {
+	switch e {
+	case ImportFormatCSV, ImportFormatTsv:
+		return true
+	}
+	return false
+}
+
+func (e ImportFormat) String() string {
+	return string(e)
+}
+
+func (e *ImportFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportFormat", str)
+	}
+	return nil
+}
+
+func (e ImportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportMode string
+
+const (
+	// Nothing is written unless every row is valid.
+	ImportModeAllOrNothing ImportMode = "ALL_OR_NOTHING"
+	// Valid rows are written and invalid ones are reported.
+	ImportModeBestEffort ImportMode = "BEST_EFFORT"
+)
+
+var AllImportMode = []ImportMode{
+	ImportModeAllOrNothing,
+	ImportModeBestEffort,
+}
+
+func (e ImportMode) IsValid() bool {
+	switch e {
+	case ImportModeAllOrNothing, ImportModeBestEffort:
+		return true
+	}
+	return false
+}
+
+func (e ImportMode) String() string {
+	return string(e)
+}
+
+func (e *ImportMode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportMode", str)
+	}
+	return nil
+}
+
+func (e ImportMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportRowStatus string
+
+const (
+	ImportRowStatusCreated   ImportRowStatus = "CREATED"
+	ImportRowStatusDuplicate ImportRowStatus = "DUPLICATE"
+	ImportRowStatusInvalid   ImportRowStatus = "INVALID"
+)
+
+var AllImportRowStatus = []ImportRowStatus{
+	ImportRowStatusCreated,
+	ImportRowStatusDuplicate,
+	ImportRowStatusInvalid,
+}
+
+func (e ImportRowStatus) IsValid() bool {
+	switch e {
+	case ImportRowStatusCreated, ImportRowStatusDuplicate, ImportRowStatusInvalid:
+		return true
+	}
+	return false
+}
+
+func (e ImportRowStatus) String() string {
+	return string(e)
+}
+
+func (e *ImportRowStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportRowStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportRowStatus", str)
+	}
+	return nil
+}
+
+func (e ImportRowStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RevisionAction string
+
+const (
+	RevisionActionCreate  RevisionAction = "CREATE"
+	RevisionActionUpdate  RevisionAction = "UPDATE"
+	RevisionActionRemove  RevisionAction = "REMOVE"
+	RevisionActionRevert  RevisionAction = "REVERT"
+	RevisionActionRestore RevisionAction = "RESTORE"
+)
+
+var AllRevisionAction = []RevisionAction{
+	RevisionActionCreate,
+	RevisionActionUpdate,
+	RevisionActionRemove,
+	RevisionActionRevert,
+	RevisionActionRestore,
+}
+
+func (e RevisionAction) IsValid() bool {
+	switch e {
+	case RevisionActionCreate, RevisionActionUpdate, RevisionActionRemove, RevisionActionRevert, RevisionActionRestore:
+		return true
+	}
+	return false
+}
+
+func (e RevisionAction) String() string {
+	return string(e)
+}
+
+func (e *RevisionAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RevisionAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RevisionAction", str)
+	}
+	return nil
+}
+
+func (e RevisionAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Roles are ordered, each one is granted everything the previous ones are.
+type Role string
+
+const (
+	// Can read the dictionary and study it.
+	RoleViewer Role = "VIEWER"
+	// Can also add and change translations.
+	RoleEditor Role = "EDITOR"
+	// Can also delete translations and manage users.
+	RoleAdmin Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleViewer,
+	RoleEditor,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleViewer, RoleEditor, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SearchMode string
+
+const (
+	SearchModePrefix    SearchMode = "PREFIX"
+	SearchModeSubstring SearchMode = "SUBSTRING"
+	SearchModeFuzzy     SearchMode = "FUZZY"
+)
+
+var AllSearchMode = []SearchMode{
+	SearchModePrefix,
+	SearchModeSubstring,
+	SearchModeFuzzy,
+}
+
+func (e SearchMode) IsValid() bool {
+	switch e {
+	case SearchModePrefix, SearchModeSubstring, SearchModeFuzzy:
+		return true
+	}
+	return false
+}
+
+func (e SearchMode) String() string {
+	return string(e)
+}
+
+func (e *SearchMode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SearchMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SearchMode", str)
+	}
+	return nil
+}
+
+func (e SearchMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WordListVisibility string
+
+const (
+	// Only the owner can see the list.
+	WordListVisibilityPrivate WordListVisibility = "PRIVATE"
+	// Anyone who knows the id of the list can see it.
+	WordListVisibilityPublic WordListVisibility = "PUBLIC"
+)
+
+var AllWordListVisibility = []WordListVisibility{
+	WordListVisibilityPrivate,
+	WordListVisibilityPublic,
+}
+
+func (e WordListVisibility) IsValid() bool {
+	switch e {
+	case WordListVisibilityPrivate, WordListVisibilityPublic:
+		return true
+	}
+	return false
+}
+
+func (e WordListVisibility) String() string {
+	return string(e)
+}
+
+func (e *WordListVisibility) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WordListVisibility(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WordListVisibility", str)
+	}
+	return nil
+}
+
+func (e WordListVisibility) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
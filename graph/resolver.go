@@ -1,7 +1,20 @@
 package graph
 
+import (
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/pgrzankowski/dictionary-app/srs"
+)
+
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
-type Resolver struct{}
+type Resolver struct {
+	// Service does the work behind every field.
+	Service services.Service
+	// Clock schedules reviews, it is replaced by a fixed clock in tests.
+	Clock srs.Clock
+	// Tokens issues the tokens returned by register and login.
+	Tokens *auth.Issuer
+}
@@ -0,0 +1,163 @@
+package graph_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/loaders"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeService serves two translations from memory and counts the batch calls.
+// Calling any other method panics.
+type fakeService struct {
+	services.Service
+	lexemeBatches   atomic.Int32
+	exampleBatches  atomic.Int32
+	revisionBatches atomic.Int32
+}
+
+func (s *fakeService) Translations(ctx context.Context) ([]*model.Translation, error) {
+	return []*model.Translation{
+		{ID: "1", SourceLexemeID: 1, TargetLexemeID: 2},
+		{ID: "2", SourceLexemeID: 1, TargetLexemeID: 3},
+	}, nil
+}
+
+func (s *fakeService) LexemesByID(ctx context.Context, ids []uint) (map[uint]*model.Lexeme, error) {
+	s.lexemeBatches.Add(1)
+	lexemes := map[uint]model.Lexeme{
+		1: {Word: "zamek", LanguageCode: "pl"},
+		2: {Word: "castle", LanguageCode: "en"},
+		3: {Word: "lock", LanguageCode: "en"},
+		4: {Word: "Schloss", LanguageCode: "de"},
+		5: {Word: "замок", LanguageCode: "uk"},
+	}
+
+	result := make(map[uint]*model.Lexeme, len(ids))
+	for _, id := range ids {
+		lexeme := lexemes[id]
+		result[id] = &lexeme
+	}
+	return result, nil
+}
+
+func (s *fakeService) ExamplesByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.Example, error) {
+	s.exampleBatches.Add(1)
+	return map[uint][]*model.Example{
+		1: {{Sentence: "Zamek stoi na wzgórzu."}},
+	}, nil
+}
+
+func (s *fakeService) RevisionsByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.TranslationRevision, error) {
+	s.revisionBatches.Add(1)
+	return map[uint][]*model.TranslationRevision{
+		1: {{Action: model.RevisionActionCreate}},
+	}, nil
+}
+
+func newTestServer(service services.Service) *handler.Server {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  &graph.Resolver{Service: service},
+		Directives: graph.DirectiveRoot{HasRole: graph.HasRole},
+	}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(loaders.Extension{Service: service})
+
+	return srv
+}
+
+func TestResolverService(t *testing.T) {
+	service := &fakeService{}
+	srv := newTestServer(service)
+
+	var response struct {
+		Translations []struct {
+			EnglishWord string
+			Source      struct{ Word string }
+			Examples    []struct{ Sentence string }
+		}
+	}
+	client.New(srv).MustPost(`{ translations { englishWord source { word } examples { sentence } } }`, &response)
+
+	if assert.Equal(t, 2, len(response.Translations), "Translations should come from the service") {
+		assert.Equal(t, "castle", response.Translations[0].EnglishWord, "EnglishWord should match")
+		assert.Equal(t, "lock", response.Translations[1].EnglishWord, "EnglishWord should match")
+		assert.Equal(t, "zamek", response.Translations[1].Source.Word, "Source should match")
+		assert.Equal(t, 1, len(response.Translations[0].Examples), "Examples should match")
+		assert.Equal(t, 0, len(response.Translations[1].Examples), "Translations without examples should get an empty list")
+	}
+	assert.Equal(t, int32(1), service.lexemeBatches.Load(), "Lexemes should be loaded in a single batch")
+	assert.Equal(t, int32(1), service.exampleBatches.Load(), "Examples should be loaded in a single batch")
+}
+
+func TestHistory(t *testing.T) {
+	service := &fakeService{}
+	srv := newTestServer(service)
+	asRole := func(role string) client.Option {
+		return func(request *client.Request) {
+			request.HTTP = request.HTTP.WithContext(auth.WithUser(request.HTTP.Context(), &gormModels.User{ID: 1, Role: role}))
+		}
+	}
+
+	var response struct {
+		Translations []struct {
+			History []struct{ Action string }
+		}
+	}
+	err := client.New(srv).Post(`{ translations { history { action } } }`, &response, asRole(auth.RoleViewer))
+	assert.Error(t, err, "Viewers should not see the history")
+
+	err = client.New(srv).Post(`{ translations { history { action } } }`, &response, asRole(auth.RoleEditor))
+	assert.NoError(t, err, "Editors should see the history")
+	if assert.Equal(t, 2, len(response.Translations), "Translations should come from the service") {
+		assert.Equal(t, "CREATE", response.Translations[0].History[0].Action, "History should match")
+		assert.Empty(t, response.Translations[1].History, "Translations without revisions should get an empty list")
+	}
+	assert.Equal(t, int32(1), service.revisionBatches.Load(), "Revisions should be loaded in a single batch")
+}
+
+// reversedService serves translations into Polish and between two other
+// languages.
+type reversedService struct {
+	fakeService
+}
+
+func (s *reversedService) Translations(ctx context.Context) ([]*model.Translation, error) {
+	return []*model.Translation{
+		{ID: "1", SourceLexemeID: 2, TargetLexemeID: 1},
+		{ID: "2", SourceLexemeID: 4, TargetLexemeID: 5},
+	}, nil
+}
+
+func TestDeprecatedTranslationFields(t *testing.T) {
+	srv := newTestServer(&reversedService{})
+
+	var response struct {
+		Translations []struct {
+			EnglishWord *string
+			PolishWord  *struct{ Word string }
+		}
+	}
+	client.New(srv).MustPost(`{ translations { englishWord polishWord { word } } }`, &response)
+
+	if assert.Equal(t, 2, len(response.Translations), "Translations should come from the service") {
+		if assert.NotNil(t, response.Translations[0].EnglishWord, "English source should be found") {
+			assert.Equal(t, "castle", *response.Translations[0].EnglishWord, "EnglishWord should be the English side")
+		}
+		if assert.NotNil(t, response.Translations[0].PolishWord, "Polish target should be found") {
+			assert.Equal(t, "zamek", response.Translations[0].PolishWord.Word, "PolishWord should be the Polish side")
+		}
+		assert.Nil(t, response.Translations[1].EnglishWord, "Translations without English should get null")
+		assert.Nil(t, response.Translations[1].PolishWord, "Translations without Polish should get null")
+	}
+}
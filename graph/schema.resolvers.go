@@ -6,15 +6,80 @@ package graph
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
-	"github.com/pgrzankowski/dictionary-app/db"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/events"
 	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/loaders"
 	"github.com/pgrzankowski/dictionary-app/services"
 )
 
+// Translation is the resolver for the translation field.
+func (r *exampleResolver) Translation(ctx context.Context, obj *model.Example) (*model.Translation, error) {
+	return loaders.For(ctx).Translation.Load(ctx, obj.TranslationID)
+}
+
+// Language is the resolver for the language field.
+func (r *lexemeResolver) Language(ctx context.Context, obj *model.Lexeme) (*model.Language, error) {
+	return loaders.For(ctx).Language.Load(ctx, obj.LanguageCode)
+}
+
+// Translations is the resolver for the translations field.
+func (r *lexemeResolver) Translations(ctx context.Context, obj *model.Lexeme) ([]*model.Translation, error) {
+	id, err := strconv.Atoi(obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	return loaders.For(ctx).TranslationsByLexeme.Load(ctx, uint(id))
+}
+
+// Register is the resolver for the register field.
+func (r *mutationResolver) Register(ctx context.Context, email string, password string) (*model.AuthPayload, error) {
+	user, err := r.Service.Register(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.authPayload(user)
+}
+
+// Login is the resolver for the login field.
+func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*model.AuthPayload, error) {
+	user, err := r.Service.Login(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.authPayload(user)
+}
+
+// AddLanguage is the resolver for the addLanguage field.
+func (r *mutationResolver) AddLanguage(ctx context.Context, code string, name string) (*model.Language, error) {
+	result, err := r.Service.AddLanguage(ctx, code, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddTranslation is the resolver for the addTranslation field.
+func (r *mutationResolver) AddTranslation(ctx context.Context, input model.TranslationInput) (*model.Translation, error) {
+	result, err := r.Service.AddTranslation(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // CreateTranslation is the resolver for the createTranslation field.
 func (r *mutationResolver) CreateTranslation(ctx context.Context, input model.NewTranslationInput) (*model.Translation, error) {
-	translation, err := services.CreateTranslation(db.GormDB, ctx, input)
+	translation, err := r.Service.CreateTranslation(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +89,7 @@ func (r *mutationResolver) CreateTranslation(ctx context.Context, input model.Ne
 
 // RemoveTranslation is the resolver for the removeTranslation field.
 func (r *mutationResolver) RemoveTranslation(ctx context.Context, id string) (bool, error) {
-	removed, err := services.RemoveTranslation(db.GormDB, ctx, id)
+	removed, err := r.Service.RemoveTranslation(ctx, id)
 	if err != nil {
 		return false, err
 	}
@@ -32,9 +97,43 @@ func (r *mutationResolver) RemoveTranslation(ctx context.Context, id string) (bo
 	return removed, nil
 }
 
+// RestoreTranslation is the resolver for the restoreTranslation field.
+func (r *mutationResolver) RestoreTranslation(ctx context.Context, id string) (*model.Translation, error) {
+	result, err := r.Service.RestoreTranslation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RevertTranslation is the resolver for the revertTranslation field.
+func (r *mutationResolver) RevertTranslation(ctx context.Context, id string, revisionID string) (*model.Translation, error) {
+	result, err := r.Service.RevertTranslation(ctx, id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ImportTranslations is the resolver for the importTranslations field.
+func (r *mutationResolver) ImportTranslations(ctx context.Context, file graphql.Upload, format *model.ImportFormat, dryRun *bool, mode *model.ImportMode) (*model.ImportReport, error) {
+	result, err := r.Service.ImportTranslations(ctx, file.File, format, dryRun, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // UpdateTranslation is the resolver for the updateTranslation field.
 func (r *mutationResolver) UpdateTranslation(ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error) {
-	updatedTranslation, err := services.UpdateTranslation(db.GormDB, ctx, input)
+	if len(input.RemoveExampleIds) > 0 && !auth.HasRole(auth.UserFromContext(ctx), auth.RoleAdmin) {
+		return nil, fmt.Errorf("%w: removing examples requires the admin role", auth.ErrForbidden)
+	}
+
+	updatedTranslation, err := r.Service.UpdateTranslation(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +141,234 @@ func (r *mutationResolver) UpdateTranslation(ctx context.Context, input model.Up
 	return updatedTranslation, nil
 }
 
+// RenameLexeme is the resolver for the renameLexeme field.
+func (r *mutationResolver) RenameLexeme(ctx context.Context, id string, word string) (*model.Lexeme, error) {
+	result, err := r.Service.RenameLexeme(ctx, id, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteLexeme is the resolver for the deleteLexeme field.
+func (r *mutationResolver) DeleteLexeme(ctx context.Context, id string, cascade *bool) (bool, error) {
+	removed, err := r.Service.DeleteLexeme(ctx, id, cascade != nil && *cascade)
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// RenamePolishWord is the resolver for the renamePolishWord field.
+func (r *mutationResolver) RenamePolishWord(ctx context.Context, id string, word string) (*model.PolishWord, error) {
+	result, err := r.Service.RenamePolishWord(ctx, id, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeletePolishWord is the resolver for the deletePolishWord field.
+func (r *mutationResolver) DeletePolishWord(ctx context.Context, id string, cascade *bool) (bool, error) {
+	removed, err := r.Service.DeletePolishWord(ctx, id, cascade != nil && *cascade)
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// AddExample is the resolver for the addExample field.
+func (r *mutationResolver) AddExample(ctx context.Context, translationID string, sentence string, position *int32) (*model.Example, error) {
+	result, err := r.Service.AddExample(ctx, translationID, sentence, position)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateExample is the resolver for the updateExample field.
+func (r *mutationResolver) UpdateExample(ctx context.Context, id string, sentence string) (*model.Example, error) {
+	result, err := r.Service.UpdateExample(ctx, id, sentence)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RemoveExample is the resolver for the removeExample field.
+func (r *mutationResolver) RemoveExample(ctx context.Context, id string) (bool, error) {
+	removed, err := r.Service.RemoveExample(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// ReorderExamples is the resolver for the reorderExamples field.
+func (r *mutationResolver) ReorderExamples(ctx context.Context, translationID string, exampleIds []string) ([]*model.Example, error) {
+	result, err := r.Service.ReorderExamples(ctx, translationID, exampleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SubmitReview is the resolver for the submitReview field.
+func (r *mutationResolver) SubmitReview(ctx context.Context, translationID string, grade int32) (*model.Review, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.SubmitReview(ctx, r.Clock, userID, translationID, grade)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetUserRole is the resolver for the setUserRole field.
+func (r *mutationResolver) SetUserRole(ctx context.Context, userID string, role model.Role) (*model.User, error) {
+	result, err := r.Service.SetUserRole(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateWordList is the resolver for the createWordList field.
+func (r *mutationResolver) CreateWordList(ctx context.Context, input model.NewWordListInput) (*model.WordList, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.CreateWordList(ctx, userID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateWordList is the resolver for the updateWordList field.
+func (r *mutationResolver) UpdateWordList(ctx context.Context, input model.UpdateWordListInput) (*model.WordList, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.UpdateWordList(ctx, userID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteWordList is the resolver for the deleteWordList field.
+func (r *mutationResolver) DeleteWordList(ctx context.Context, id string) (bool, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := r.Service.DeleteWordList(ctx, userID, id)
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// AddToWordList is the resolver for the addToWordList field.
+func (r *mutationResolver) AddToWordList(ctx context.Context, listID string, translationID string, position *int32) (*model.WordList, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.AddToWordList(ctx, userID, listID, translationID, position)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RemoveFromWordList is the resolver for the removeFromWordList field.
+func (r *mutationResolver) RemoveFromWordList(ctx context.Context, listID string, translationID string) (*model.WordList, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.RemoveFromWordList(ctx, userID, listID, translationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReorderWordList is the resolver for the reorderWordList field.
+func (r *mutationResolver) ReorderWordList(ctx context.Context, listID string, translationIds []string) (*model.WordList, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.ReorderWordList(ctx, userID, listID, translationIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Translations is the resolver for the translations field.
+func (r *polishWordResolver) Translations(ctx context.Context, obj *model.PolishWord) ([]*model.Translation, error) {
+	id, err := strconv.Atoi(obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	return loaders.For(ctx).TranslationsByLexeme.Load(ctx, uint(id))
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return nil, nil
+	}
+
+	return services.ConvertUser(user), nil
+}
+
 // Translations is the resolver for the translations field.
 func (r *queryResolver) Translations(ctx context.Context) ([]*model.Translation, error) {
-	result, err := services.Translations(db.GormDB, ctx)
+	result, err := r.Service.Translations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// TranslationsConnection is the resolver for the translationsConnection field.
+func (r *queryResolver) TranslationsConnection(ctx context.Context, first *int32, after *string, last *int32, before *string) (*model.TranslationConnection, error) {
+	result, err := r.Service.TranslationsConnection(ctx, first, after, last, before)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +378,17 @@ func (r *queryResolver) Translations(ctx context.Context) ([]*model.Translation,
 
 // Translation is the resolver for the translation field.
 func (r *queryResolver) Translation(ctx context.Context, id string) (*model.Translation, error) {
-	result, err := services.Translation(db.GormDB, ctx, id)
+	result, err := r.Service.Translation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeletedTranslations is the resolver for the deletedTranslations field.
+func (r *queryResolver) DeletedTranslations(ctx context.Context, page *model.PageInput) (*model.TranslationConnection, error) {
+	result, err := r.Service.DeletedTranslations(ctx, page)
 	if err != nil {
 		return nil, err
 	}
@@ -62,11 +396,241 @@ func (r *queryResolver) Translation(ctx context.Context, id string) (*model.Tran
 	return result, nil
 }
 
+// SearchTranslations is the resolver for the searchTranslations field.
+func (r *queryResolver) SearchTranslations(ctx context.Context, query string, language *string, mode *model.SearchMode, limit *int32) ([]*model.SearchResult, error) {
+	result, err := r.Service.SearchTranslations(ctx, query, language, mode, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// TranslationsByEnglish is the resolver for the translationsByEnglish field.
+func (r *queryResolver) TranslationsByEnglish(ctx context.Context, word string) ([]*model.TranslationGroup, error) {
+	result, err := r.Service.TranslationsByEnglish(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// TranslationsByPolish is the resolver for the translationsByPolish field.
+func (r *queryResolver) TranslationsByPolish(ctx context.Context, word string) ([]*model.TranslationGroup, error) {
+	result, err := r.Service.TranslationsByPolish(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DueReviews is the resolver for the dueReviews field.
+func (r *queryResolver) DueReviews(ctx context.Context, limit *int32) ([]*model.Review, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.DueReviews(ctx, r.Clock, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MyWordLists is the resolver for the myWordLists field.
+func (r *queryResolver) MyWordLists(ctx context.Context) ([]*model.WordList, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Service.MyWordLists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// WordList is the resolver for the wordList field.
+func (r *queryResolver) WordList(ctx context.Context, id string) (*model.WordList, error) {
+	result, err := r.Service.WordList(ctx, optionalUserID(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Languages is the resolver for the languages field.
+func (r *queryResolver) Languages(ctx context.Context) ([]*model.Language, error) {
+	result, err := r.Service.Languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Lexeme is the resolver for the lexeme field.
+func (r *queryResolver) Lexeme(ctx context.Context, id string) (*model.Lexeme, error) {
+	result, err := r.Service.Lexeme(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// LexemeByWord is the resolver for the lexemeByWord field.
+func (r *queryResolver) LexemeByWord(ctx context.Context, language string, word string) (*model.Lexeme, error) {
+	result, err := r.Service.LexemeByWord(ctx, language, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Lexemes is the resolver for the lexemes field.
+func (r *queryResolver) Lexemes(ctx context.Context, filter *model.LexemeFilter, page *model.PageInput) (*model.LexemeConnection, error) {
+	result, err := r.Service.Lexemes(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PolishWord is the resolver for the polishWord field.
+func (r *queryResolver) PolishWord(ctx context.Context, id string) (*model.PolishWord, error) {
+	result, err := r.Service.PolishWord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PolishWordByText is the resolver for the polishWordByText field.
+func (r *queryResolver) PolishWordByText(ctx context.Context, word string) (*model.PolishWord, error) {
+	result, err := r.Service.PolishWordByText(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PolishWords is the resolver for the polishWords field.
+func (r *queryResolver) PolishWords(ctx context.Context, filter *model.PolishWordFilter, page *model.PageInput) (*model.PolishWordConnection, error) {
+	result, err := r.Service.PolishWords(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// TranslationCreated is the resolver for the translationCreated field.
+func (r *subscriptionResolver) TranslationCreated(ctx context.Context, polishWord *string) (<-chan *model.Translation, error) {
+	return r.Service.TranslationEvents(ctx, events.TranslationCreated, polishWord), nil
+}
+
+// TranslationUpdated is the resolver for the translationUpdated field.
+func (r *subscriptionResolver) TranslationUpdated(ctx context.Context, polishWord *string) (<-chan *model.Translation, error) {
+	return r.Service.TranslationEvents(ctx, events.TranslationUpdated, polishWord), nil
+}
+
+// TranslationRemoved is the resolver for the translationRemoved field.
+func (r *subscriptionResolver) TranslationRemoved(ctx context.Context, polishWord *string) (<-chan *model.Translation, error) {
+	return r.Service.TranslationEvents(ctx, events.TranslationRemoved, polishWord), nil
+}
+
+// EnglishWord is the resolver for the englishWord field.
+func (r *translationResolver) EnglishWord(ctx context.Context, obj *model.Translation) (*string, error) {
+	english, err := lexemeIn(ctx, obj, englishLanguageCode, false)
+	if err != nil || english == nil {
+		return nil, err
+	}
+
+	return &english.Word, nil
+}
+
+// Source is the resolver for the source field.
+func (r *translationResolver) Source(ctx context.Context, obj *model.Translation) (*model.Lexeme, error) {
+	return loaders.For(ctx).Lexeme.Load(ctx, obj.SourceLexemeID)
+}
+
+// Target is the resolver for the target field.
+func (r *translationResolver) Target(ctx context.Context, obj *model.Translation) (*model.Lexeme, error) {
+	return loaders.For(ctx).Lexeme.Load(ctx, obj.TargetLexemeID)
+}
+
+// PolishWord is the resolver for the polishWord field.
+func (r *translationResolver) PolishWord(ctx context.Context, obj *model.Translation) (*model.PolishWord, error) {
+	polish, err := lexemeIn(ctx, obj, polishLanguageCode, true)
+	if err != nil || polish == nil {
+		return nil, err
+	}
+
+	return &model.PolishWord{
+		ID:        polish.ID,
+		Word:      polish.Word,
+		CreatedAt: polish.CreatedAt,
+		UpdatedAt: polish.UpdatedAt,
+	}, nil
+}
+
+// Examples is the resolver for the examples field.
+func (r *translationResolver) Examples(ctx context.Context, obj *model.Translation) ([]*model.Example, error) {
+	id, err := strconv.Atoi(obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	return loaders.For(ctx).ExamplesByTranslation.Load(ctx, uint(id))
+}
+
+// History is the resolver for the history field.
+func (r *translationResolver) History(ctx context.Context, obj *model.Translation) ([]*model.TranslationRevision, error) {
+	id, err := strconv.Atoi(obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	return loaders.For(ctx).RevisionsByTranslation.Load(ctx, uint(id))
+}
+
+// Example returns ExampleResolver implementation.
+func (r *Resolver) Example() ExampleResolver { return &exampleResolver{r} }
+
+// Lexeme returns LexemeResolver implementation.
+func (r *Resolver) Lexeme() LexemeResolver { return &lexemeResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// PolishWord returns PolishWordResolver implementation.
+func (r *Resolver) PolishWord() PolishWordResolver { return &polishWordResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+// Translation returns TranslationResolver implementation.
+func (r *Resolver) Translation() TranslationResolver { return &translationResolver{r} }
+
+type exampleResolver struct{ *Resolver }
+type lexemeResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type polishWordResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+type translationResolver struct{ *Resolver }
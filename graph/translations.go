@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/loaders"
+)
+
+// The languages of the deprecated Translation fields written before other
+// languages were supported.
+const (
+	polishLanguageCode  = "pl"
+	englishLanguageCode = "en"
+)
+
+// lexemeIn returns the lexeme of translation in language, looking at the side
+// given by preferSource first, or nil when neither side is in language.
+func lexemeIn(ctx context.Context, translation *model.Translation, language string, preferSource bool) (*model.Lexeme, error) {
+	ids := []uint{translation.SourceLexemeID, translation.TargetLexemeID}
+	if !preferSource {
+		ids[0], ids[1] = ids[1], ids[0]
+	}
+
+	lexemes, err := loaders.For(ctx).Lexeme.LoadAll(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, lexeme := range lexemes {
+		if lexeme.LanguageCode == language {
+			return lexeme, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/migrations"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds the checks of readyHandler, so that probes get an
+// answer while the database hangs.
+const readinessTimeout = 2 * time.Second
+
+// healthHandler reports that the server is alive, which it is whenever it
+// responds at all.
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// readyHandler reports whether the server can handle requests: database
+// responds and has no pending migrations. It responds with 503 Service
+// Unavailable and the reason otherwise.
+func readyHandler(database *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := checkReady(ctx, database); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+func checkReady(ctx context.Context, database *gorm.DB) error {
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unavailable: %w", err)
+	}
+
+	pending, err := migrations.Pending(database.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migrations pending, starting with %d %s", len(pending), pending[0].Version, pending[0].Name)
+	}
+
+	return nil
+}
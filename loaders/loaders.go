@@ -0,0 +1,93 @@
+// Package loaders batches the queries behind nested GraphQL fields, so that
+// resolving a field on many objects costs one query instead of one per object.
+package loaders
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// Loaders cache what they load, so a fresh set is used for every response.
+type Loaders struct {
+	Lexeme                 *dataloadgen.Loader[uint, *model.Lexeme]
+	Language               *dataloadgen.Loader[string, *model.Language]
+	Translation            *dataloadgen.Loader[uint, *model.Translation]
+	TranslationsByLexeme   *dataloadgen.Loader[uint, []*model.Translation]
+	ExamplesByTranslation  *dataloadgen.Loader[uint, []*model.Example]
+	RevisionsByTranslation *dataloadgen.Loader[uint, []*model.TranslationRevision]
+}
+
+func New(service services.Service) *Loaders {
+	return &Loaders{
+		Lexeme:                 dataloadgen.NewMappedLoader(service.LexemesByID),
+		Language:               dataloadgen.NewMappedLoader(service.LanguagesByCode),
+		Translation:            dataloadgen.NewMappedLoader(service.TranslationsByID),
+		TranslationsByLexeme:   dataloadgen.NewLoader(batchAll(service.TranslationsByLexeme)),
+		ExamplesByTranslation:  dataloadgen.NewLoader(batchAll(service.ExamplesByTranslation)),
+		RevisionsByTranslation: dataloadgen.NewLoader(batchAll(service.RevisionsByTranslation)),
+	}
+}
+
+// batchAll adapts a service method listing the relations of each key to a
+// loader, which gives keys without a match an empty list. Keys without a
+// match fail with dataloadgen.ErrNotFound in the mapped loaders instead.
+func batchAll[K comparable, V any](
+	fetch func(context.Context, []K) (map[K][]V, error),
+) func(context.Context, []K) ([][]V, []error) {
+	return func(ctx context.Context, keys []K) ([][]V, []error) {
+		found, err := fetch(ctx, keys)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		result := make([][]V, len(keys))
+		for i, key := range keys {
+			result[i] = found[key]
+			if result[i] == nil {
+				result[i] = []V{}
+			}
+		}
+
+		return result, nil
+	}
+}
+
+type contextKey struct{}
+
+// WithLoaders returns a copy of ctx carrying loaders.
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, contextKey{}, loaders)
+}
+
+// For returns the loaders of the response being resolved in ctx.
+func For(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(contextKey{}).(*Loaders)
+	return loaders
+}
+
+// Extension gives every response its own loaders. Subscriptions send several
+// responses, each of which has to see the data as it is when it is sent.
+type Extension struct {
+	Service services.Service
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = Extension{}
+
+func (Extension) ExtensionName() string {
+	return "Loaders"
+}
+
+func (Extension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	return next(WithLoaders(ctx, New(e.Service)))
+}
@@ -0,0 +1,109 @@
+package loaders_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/joho/godotenv"
+	"github.com/pgrzankowski/dictionary-app/db"
+	"github.com/pgrzankowski/dictionary-app/graph"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/loaders"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := godotenv.Load("../.env"); err != nil && !os.IsNotExist(err) {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
+}
+
+const nestedQuery = `query ($first: Int) {
+	translationsConnection(first: $first) {
+		edges {
+			node {
+				englishWord
+				source { word language { name } translations { id } }
+				target { word }
+				polishWord { word translations { id } }
+				examples { sentence translation { id } }
+			}
+		}
+	}
+}`
+
+type nestedResponse struct {
+	TranslationsConnection struct {
+		Edges []struct {
+			Node struct {
+				EnglishWord string
+				Examples    []struct{ Sentence string }
+			}
+		}
+	}
+}
+
+func TestNestedFieldsQueryCount(t *testing.T) {
+
+	testDB, err := db.ConnectTestGORM("loaders_test")
+	if err != nil {
+		t.Skipf("test database unavailable: %v", err)
+	}
+	if err := testDB.Exec("TRUNCATE TABLE examples, translations, lexemes, users, translation_revisions RESTART IDENTITY CASCADE").Error; err != nil {
+		t.Fatalf("failed to truncate tables: %v", err)
+	}
+	service := services.New(testDB)
+
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		_, err := service.CreateTranslation(ctx, model.NewTranslationInput{
+			PolishWord:  fmt.Sprintf("słowo %d", i),
+			EnglishWord: fmt.Sprintf("word %d", i),
+			Examples:    []*model.NewExampleInput{{Sentence: "Pierwsze zdanie."}, {Sentence: "Drugie zdanie."}},
+		})
+		if err != nil {
+			t.Fatalf("failed to create translation: %v", err)
+		}
+	}
+
+	var queries atomic.Int32
+	err = testDB.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) {
+		queries.Add(1)
+	})
+	if err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+	defer testDB.Callback().Query().Remove("test:count_queries")
+
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  &graph.Resolver{Service: service},
+		Directives: graph.DirectiveRoot{HasRole: graph.HasRole},
+	}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(loaders.Extension{Service: service})
+	c := client.New(srv)
+
+	count := func(first int) int32 {
+		queries.Store(0)
+		var response nestedResponse
+		c.MustPost(nestedQuery, &response, client.Var("first", first))
+		assert.Equal(t, first, len(response.TranslationsConnection.Edges), "Page size should match")
+		for _, edge := range response.TranslationsConnection.Edges {
+			assert.Equal(t, 2, len(edge.Node.Examples), "Examples should be loaded")
+		}
+		return queries.Load()
+	}
+
+	few, many := count(5), count(20)
+	assert.Equal(t, few, many, "Nested fields should cost the same number of queries regardless of page size")
+	assert.LessOrEqual(t, many, int32(8), "Each nested relation should be loaded with a single query")
+}
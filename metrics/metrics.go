@@ -0,0 +1,153 @@
+// Package metrics exposes the state of the server to Prometheus: the GraphQL
+// operations it runs, the connection pool of its database and the Go runtime.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "dictionary"
+
+// NewRegistry returns a registry collecting the Go runtime and process
+// metrics and the statistics of the connection pool of database.
+func NewRegistry(database *sql.DB) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(database, namespace),
+	)
+
+	return registry
+}
+
+// Extension counts the GraphQL operations and their errors and measures how
+// long queries and mutations take, by operation name and type. Requests
+// rejected before they run, because they cannot be parsed or are invalid, are
+// only counted as errors.
+//
+// Operation names are chosen by the clients, so only the first
+// maxOperationNames names seen get labels of their own and later ones are
+// counted as "other".
+type Extension struct {
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	names      *operationNames
+}
+
+// maxOperationNames bounds the number of operation labels, and so the number
+// of series, an Extension creates.
+const maxOperationNames = 100
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.ResponseInterceptor
+} = Extension{}
+
+// NewExtension returns an Extension recording its metrics in registerer.
+func NewExtension(registerer prometheus.Registerer) Extension {
+	labels := []string{"operation", "type"}
+	e := Extension{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_operations_total",
+			Help:      "GraphQL operations run, subscriptions counted once.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_errors_total",
+			Help:      "Errors in GraphQL responses.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "graphql_operation_duration_seconds",
+			Help:      "Time from reading a GraphQL query or mutation to its response.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		names: &operationNames{limit: maxOperationNames, seen: make(map[string]bool)},
+	}
+	registerer.MustRegister(e.operations, e.errors, e.duration)
+
+	return e
+}
+
+func (Extension) ExtensionName() string {
+	return "Metrics"
+}
+
+func (Extension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	e.operations.WithLabelValues(e.operationLabels(ctx)...).Inc()
+	return next(ctx)
+}
+
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	response := next(ctx)
+	labels := e.operationLabels(ctx)
+
+	if response != nil && len(response.Errors) > 0 {
+		e.errors.WithLabelValues(labels...).Add(float64(len(response.Errors)))
+	}
+	// Subscriptions send a response per event, long after they started.
+	if graphql.HasOperationContext(ctx) && labels[1] != "subscription" {
+		start := graphql.GetOperationContext(ctx).Stats.OperationStart
+		if !start.IsZero() {
+			e.duration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		}
+	}
+
+	return response
+}
+
+// operationLabels returns the name and type of the operation in ctx. Both are
+// "unknown" for requests that could not be parsed or name no operation of
+// their document, and operations without a name are "anonymous".
+func (e Extension) operationLabels(ctx context.Context) []string {
+	if !graphql.HasOperationContext(ctx) || graphql.GetOperationContext(ctx).Operation == nil {
+		return []string{"unknown", "unknown"}
+	}
+
+	operation := graphql.GetOperationContext(ctx).Operation
+	name := operation.Name
+	if name == "" {
+		name = "anonymous"
+	}
+
+	return []string{e.names.label(name), string(operation.Operation)}
+}
+
+// operationNames remembers the operation names that have labels of their own.
+type operationNames struct {
+	limit int
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// label returns name if it has a label of its own, or can still get one, and
+// "other" otherwise.
+func (n *operationNames) label(name string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.seen[name] {
+		if len(n.seen) >= n.limit {
+			return "other"
+		}
+		n.seen[name] = true
+	}
+
+	return name
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func withOperation(name string, operation ast.Operation) context.Context {
+	return graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Name: name, Operation: operation},
+		Stats:     graphql.Stats{OperationStart: time.Now().Add(-time.Second)},
+	})
+}
+
+// run passes an operation in ctx through e, responding with errs.
+func run(e Extension, ctx context.Context, errs ...*gqlerror.Error) {
+	e.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		return func(ctx context.Context) *graphql.Response {
+			return e.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+				return &graphql.Response{Errors: errs}
+			})
+		}
+	})(ctx)
+}
+
+func TestExtension(t *testing.T) {
+	e := NewExtension(prometheus.NewRegistry())
+
+	run(e, withOperation("Translations", ast.Query))
+	run(e, withOperation("Translations", ast.Query), gqlerror.Errorf("first"), gqlerror.Errorf("second"))
+	run(e, withOperation("", ast.Mutation))
+	run(e, withOperation("Updates", ast.Subscription))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(e.operations.WithLabelValues("Translations", "query")), "Operations should be counted by name and type")
+	assert.Equal(t, 1.0, testutil.ToFloat64(e.operations.WithLabelValues("anonymous", "mutation")), "Operations without a name should be anonymous")
+	assert.Equal(t, 2.0, testutil.ToFloat64(e.errors.WithLabelValues("Translations", "query")), "Every error should be counted")
+	assert.Equal(t, 0.0, testutil.ToFloat64(e.errors.WithLabelValues("anonymous", "mutation")), "Successful operations should not count as errors")
+	assert.Equal(t, 2, testutil.CollectAndCount(e.duration), "Duration should be measured for queries and mutations only")
+
+	e.InterceptResponse(context.Background(), func(ctx context.Context) *graphql.Response {
+		return &graphql.Response{Errors: gqlerror.List{gqlerror.Errorf("cannot parse")}}
+	})
+	assert.Equal(t, 1.0, testutil.ToFloat64(e.errors.WithLabelValues("unknown", "unknown")), "Rejected requests should be counted as errors")
+}
+
+func TestOperationNameLimit(t *testing.T) {
+	e := NewExtension(prometheus.NewRegistry())
+	e.names.limit = 2
+
+	for _, name := range []string{"First", "Second", "Third", "First", "Fourth"} {
+		run(e, withOperation(name, ast.Query))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(e.operations.WithLabelValues("First", "query")), "Names seen first should keep their label")
+	assert.Equal(t, 1.0, testutil.ToFloat64(e.operations.WithLabelValues("Second", "query")), "Names seen first should keep their label")
+	assert.Equal(t, 2.0, testutil.ToFloat64(e.operations.WithLabelValues("other", "query")), "Names over the limit should be counted as other")
+	assert.Equal(t, 3, testutil.CollectAndCount(e.operations), "Names over the limit should not create series")
+}
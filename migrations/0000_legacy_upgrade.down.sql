@@ -0,0 +1,2 @@
+-- The old layouts are not restored.
+SELECT 1;
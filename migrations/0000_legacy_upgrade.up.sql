@@ -0,0 +1,141 @@
+-- Databases created by earlier versions of the server, which used GORM's
+-- AutoMigrate, may be in any of the layouts the models went through. This
+-- brings them to the layout 0001_initial_schema takes over, and finds nothing
+-- to do on databases created by the migrations. Every step only runs if the
+-- column or table it changes is still in its old form.
+
+CREATE FUNCTION pg_temp.has_table(tbl text) RETURNS boolean AS $$
+	SELECT EXISTS (
+		SELECT 1 FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = tbl
+	)
+$$ LANGUAGE sql;
+
+CREATE FUNCTION pg_temp.has_column(tbl text, col text) RETURNS boolean AS $$
+	SELECT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = tbl AND column_name = col
+	)
+$$ LANGUAGE sql;
+
+CREATE FUNCTION pg_temp.has_constraint(tbl text, con text) RETURNS boolean AS $$
+	SELECT EXISTS (
+		SELECT 1 FROM information_schema.table_constraints
+		WHERE table_schema = current_schema() AND table_name = tbl AND constraint_name = con
+	)
+$$ LANGUAGE sql;
+
+-- Users registered before roles existed start out as viewers, so the oldest
+-- of them is made an admin who can promote the others.
+DO $$
+BEGIN
+	IF pg_temp.has_table('users') AND NOT pg_temp.has_column('users', 'role') THEN
+		ALTER TABLE "users" ADD COLUMN "role" text NOT NULL DEFAULT 'viewer';
+		UPDATE "users" SET "role" = 'admin' WHERE "id" = (SELECT MIN("id") FROM "users");
+	END IF;
+END $$;
+
+-- Translations from Polish to English stored polish_word_id and english_word.
+-- The Polish words and every English spelling become lexemes connected by
+-- directed translations.
+DO $$
+BEGIN
+	IF NOT pg_temp.has_column('translations', 'english_word') THEN
+		RETURN;
+	END IF;
+
+	CREATE TABLE IF NOT EXISTS "languages" (
+		"code" varchar(3),
+		"name" text NOT NULL,
+		"created_at" timestamptz,
+		"updated_at" timestamptz,
+		PRIMARY KEY ("code")
+	);
+	INSERT INTO "languages" ("code", "name", "created_at", "updated_at") VALUES
+		('pl', 'Polish', now(), now()),
+		('en', 'English', now(), now())
+	ON CONFLICT DO NOTHING;
+
+	CREATE TABLE IF NOT EXISTS "lexemes" (
+		"id" bigserial,
+		"language_code" varchar(3) NOT NULL,
+		"word" text NOT NULL,
+		"created_at" timestamptz,
+		"updated_at" timestamptz,
+		"deleted_at" timestamptz,
+		PRIMARY KEY ("id"),
+		CONSTRAINT "fk_lexemes_language" FOREIGN KEY ("language_code") REFERENCES "languages"("code")
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS "idx_lexeme_language_word" ON "lexemes" ("language_code", "word") WHERE deleted_at IS NULL;
+
+	INSERT INTO "lexemes" ("language_code", "word", "created_at", "updated_at")
+		SELECT 'pl', "word", "created_at", "updated_at" FROM "polish_words"
+		ON CONFLICT DO NOTHING;
+	INSERT INTO "lexemes" ("language_code", "word", "created_at", "updated_at")
+		SELECT 'en', "english_word", MIN("created_at"), MAX("updated_at") FROM "translations"
+		GROUP BY "english_word"
+		ON CONFLICT DO NOTHING;
+
+	ALTER TABLE "translations"
+		ADD COLUMN "source_lexeme_id" bigint,
+		ADD COLUMN "target_lexeme_id" bigint;
+	UPDATE "translations" t SET "source_lexeme_id" = l."id"
+		FROM "polish_words" p JOIN "lexemes" l ON l."language_code" = 'pl' AND l."word" = p."word"
+		WHERE p."id" = t."polish_word_id";
+	UPDATE "translations" t SET "target_lexeme_id" = l."id"
+		FROM "lexemes" l
+		WHERE l."language_code" = 'en' AND l."word" = t."english_word";
+	ALTER TABLE "translations"
+		ALTER COLUMN "source_lexeme_id" SET NOT NULL,
+		ALTER COLUMN "target_lexeme_id" SET NOT NULL,
+		DROP COLUMN "polish_word_id",
+		DROP COLUMN "english_word",
+		ADD CONSTRAINT "fk_translations_target_lexeme" FOREIGN KEY ("target_lexeme_id") REFERENCES "lexemes"("id"),
+		ADD CONSTRAINT "fk_lexemes_translations" FOREIGN KEY ("source_lexeme_id") REFERENCES "lexemes"("id");
+
+	DROP TABLE "polish_words";
+END $$;
+
+-- The unique indexes only cover rows that are not soft deleted, so the ones
+-- created before soft deletes are recreated as partial indexes by
+-- 0001_initial_schema.
+DO $$
+BEGIN
+	IF pg_temp.has_table('lexemes') AND NOT pg_temp.has_column('lexemes', 'deleted_at') THEN
+		DROP INDEX IF EXISTS "idx_lexeme_language_word";
+		ALTER TABLE "lexemes" ADD COLUMN "deleted_at" timestamptz;
+	END IF;
+	IF pg_temp.has_table('translations') AND NOT pg_temp.has_column('translations', 'deleted_at') THEN
+		DROP INDEX IF EXISTS "idx_translation_source_target";
+		ALTER TABLE "translations" ADD COLUMN "deleted_at" timestamptz;
+	END IF;
+	IF pg_temp.has_table('examples') THEN
+		ALTER TABLE "examples"
+			ADD COLUMN IF NOT EXISTS "position" bigint NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+	END IF;
+END $$;
+
+-- Reviews recorded before user accounts existed were shared by everyone and
+-- cannot be attributed to a user.
+DO $$
+BEGIN
+	IF pg_temp.has_table('reviews') AND NOT pg_temp.has_constraint('reviews', 'fk_reviews_user') THEN
+		CREATE TABLE IF NOT EXISTS "users" (
+			"id" bigserial,
+			"email" text NOT NULL,
+			"password_hash" text NOT NULL,
+			"role" text NOT NULL DEFAULT 'viewer',
+			"created_at" timestamptz,
+			"updated_at" timestamptz,
+			PRIMARY KEY ("id")
+		);
+		DELETE FROM "reviews" WHERE "user_id" NOT IN (SELECT "id" FROM "users");
+		ALTER TABLE "reviews"
+			ADD CONSTRAINT "fk_reviews_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE;
+	END IF;
+END $$;
+
+DROP FUNCTION pg_temp.has_table(text);
+DROP FUNCTION pg_temp.has_column(text, text);
+DROP FUNCTION pg_temp.has_constraint(text, text);
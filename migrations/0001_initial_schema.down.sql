@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS
+	"translation_revisions",
+	"word_list_items",
+	"word_lists",
+	"reviews",
+	"examples",
+	"translations",
+	"lexemes",
+	"languages",
+	"users";
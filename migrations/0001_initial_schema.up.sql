@@ -0,0 +1,129 @@
+-- The schema as AutoMigrate left it. Everything is created only if missing, so
+-- that databases set up before versioned migrations, once 0000_legacy_upgrade
+-- has brought them to this layout, are taken over as they are.
+
+CREATE TABLE IF NOT EXISTS "users" (
+	"id" bigserial,
+	"email" text NOT NULL,
+	"password_hash" text NOT NULL,
+	"role" text NOT NULL DEFAULT 'viewer',
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "languages" (
+	"code" varchar(3),
+	"name" text NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("code")
+);
+
+INSERT INTO "languages" ("code", "name", "created_at", "updated_at") VALUES
+	('pl', 'Polish', now(), now()),
+	('en', 'English', now(), now()),
+	('de', 'German', now(), now()),
+	('uk', 'Ukrainian', now(), now())
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "lexemes" (
+	"id" bigserial,
+	"language_code" varchar(3) NOT NULL,
+	"word" text NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_lexemes_language" FOREIGN KEY ("language_code") REFERENCES "languages"("code")
+);
+CREATE INDEX IF NOT EXISTS "idx_lexemes_created_at_id" ON "lexemes" ("created_at", "id");
+CREATE INDEX IF NOT EXISTS "idx_lexemes_deleted_at" ON "lexemes" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_lexeme_language_word" ON "lexemes" ("language_code", "word") WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "translations" (
+	"id" bigserial,
+	"source_lexeme_id" bigint NOT NULL,
+	"target_lexeme_id" bigint NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_translations_target_lexeme" FOREIGN KEY ("target_lexeme_id") REFERENCES "lexemes"("id"),
+	CONSTRAINT "fk_lexemes_translations" FOREIGN KEY ("source_lexeme_id") REFERENCES "lexemes"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_translations_created_at_id" ON "translations" ("created_at", "id");
+CREATE INDEX IF NOT EXISTS "idx_translations_deleted_at" ON "translations" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_translations_target_lexeme_id" ON "translations" ("target_lexeme_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_translation_source_target" ON "translations" ("source_lexeme_id", "target_lexeme_id") WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "examples" (
+	"id" bigserial,
+	"translation_id" bigint NOT NULL,
+	"sentence" text NOT NULL,
+	"position" bigint NOT NULL DEFAULT 0,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_translations_examples" FOREIGN KEY ("translation_id") REFERENCES "translations"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_examples_deleted_at" ON "examples" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "reviews" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"translation_id" bigint NOT NULL,
+	"ease_factor" decimal NOT NULL,
+	"interval" bigint NOT NULL,
+	"repetitions" bigint NOT NULL,
+	"due_at" timestamptz NOT NULL,
+	"last_reviewed_at" timestamptz,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_reviews_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE,
+	CONSTRAINT "fk_reviews_translation" FOREIGN KEY ("translation_id") REFERENCES "translations"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_reviews_user_due" ON "reviews" ("user_id", "due_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_review_user_translation" ON "reviews" ("user_id", "translation_id");
+
+CREATE TABLE IF NOT EXISTS "word_lists" (
+	"id" bigserial,
+	"owner_id" bigint NOT NULL,
+	"name" text NOT NULL,
+	"visibility" text NOT NULL DEFAULT 'private',
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_word_lists_owner" FOREIGN KEY ("owner_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_word_list_owner_name" ON "word_lists" ("owner_id", "name");
+
+CREATE TABLE IF NOT EXISTS "word_list_items" (
+	"id" bigserial,
+	"word_list_id" bigint NOT NULL,
+	"translation_id" bigint NOT NULL,
+	"position" bigint NOT NULL DEFAULT 0,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_word_list_items_translation" FOREIGN KEY ("translation_id") REFERENCES "translations"("id") ON DELETE CASCADE,
+	CONSTRAINT "fk_word_lists_items" FOREIGN KEY ("word_list_id") REFERENCES "word_lists"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_word_list_items_translation_id" ON "word_list_items" ("translation_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_word_list_item" ON "word_list_items" ("word_list_id", "translation_id");
+
+-- Revisions outlive their translation, so neither the translation nor the
+-- actor is a foreign key.
+CREATE TABLE IF NOT EXISTS "translation_revisions" (
+	"id" bigserial,
+	"translation_id" bigint NOT NULL,
+	"actor_id" bigint,
+	"action" text NOT NULL,
+	"before" jsonb,
+	"after" jsonb,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_translation_revisions_translation_id" ON "translation_revisions" ("translation_id");
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS translation_revisions_append_only ON translation_revisions;
+DROP FUNCTION IF EXISTS forbid_revision_changes();
@@ -0,0 +1,8 @@
+-- Translation revisions are append-only.
+CREATE OR REPLACE FUNCTION forbid_revision_changes() RETURNS trigger
+	AS $$ BEGIN RAISE EXCEPTION 'translation revisions are append-only'; END $$
+	LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER translation_revisions_append_only
+	BEFORE UPDATE OR DELETE ON translation_revisions
+	FOR EACH ROW EXECUTE FUNCTION forbid_revision_changes();
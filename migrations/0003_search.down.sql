@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_lexemes_word_trgm;
+DROP FUNCTION IF EXISTS f_unaccent(text);
+DROP EXTENSION IF EXISTS fuzzystrmatch;
+DROP EXTENSION IF EXISTS unaccent;
+DROP EXTENSION IF EXISTS pg_trgm;
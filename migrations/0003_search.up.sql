@@ -0,0 +1,13 @@
+-- The extensions and expression indexes used by translation search.
+-- unaccent() itself is only STABLE, so it is wrapped in an IMMUTABLE function
+-- that can be used inside index expressions.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch;
+
+CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+	AS $$ SELECT public.unaccent('public.unaccent', $1) $$
+	LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_lexemes_word_trgm
+	ON lexemes USING gin (f_unaccent(lower(word)) gin_trgm_ops);
@@ -0,0 +1,313 @@
+// Package migrations keeps the database schema up to date. Every change is a
+// numbered pair of SQL files embedded in the binary, NNNN_name.up.sql making
+// it and NNNN_name.down.sql undoing it, and the applied ones are recorded in
+// the schema_migrations table.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Migration is a numbered change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// State is a migration and when it was applied, nil while it is pending.
+type State struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// lockKey identifies the advisory lock held while migrating, which keeps
+// instances starting at the same time from applying a migration twice.
+const lockKey = 4_237_110_902
+
+// Up applies the pending migrations in order and returns them.
+func Up(db *gorm.DB) ([]Migration, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrator{table: "schema_migrations", lockKey: lockKey, migrations: all}.up(db)
+}
+
+// Down undoes the last steps applied migrations, latest first, and returns
+// them.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrator{table: "schema_migrations", lockKey: lockKey, migrations: all}.down(db, steps)
+}
+
+// Status lists the migrations together with the applied ones this build does
+// not know about, ordered by version.
+func Status(db *gorm.DB) ([]State, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrator{table: "schema_migrations", lockKey: lockKey, migrations: all}.status(db)
+}
+
+// Pending returns the migrations not applied yet, ordered by version. Unlike
+// Up, Down and Status it does not wait for a migration in progress, which
+// makes it cheap enough for readiness checks.
+func Pending(db *gorm.DB) ([]Migration, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrator{table: "schema_migrations", lockKey: lockKey, migrations: all}.pending(db)
+}
+
+// All returns the embedded migrations ordered by version.
+func All() ([]Migration, error) {
+	return load(files)
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+func load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		match := fileName.FindStringSubmatch(name)
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", name, err)
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d %s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		result = append(result, *migration)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+// migrator runs migrations recorded in table while holding the advisory lock
+// lockKey.
+type migrator struct {
+	table      string
+	lockKey    int64
+	migrations []Migration
+}
+
+// appliedMigration is a row of the migrations table.
+type appliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+func (m migrator) up(db *gorm.DB) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(db, func(conn *gorm.DB) error {
+		done, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err := conn.Transaction(func(transaction *gorm.DB) error {
+				if err := transaction.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return transaction.
+					Exec("INSERT INTO "+m.table+" (version, name) VALUES (?, ?)", migration.Version, migration.Name).
+					Error
+			}); err != nil {
+				return fmt.Errorf("migration %d %s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+func (m migrator) down(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	var undone []Migration
+	err := m.locked(db, func(conn *gorm.DB) error {
+		var versions []int
+		if err := conn.
+			Table(m.table).
+			Order("version DESC").
+			Limit(steps).
+			Pluck("version", &versions).Error; err != nil {
+			return fmt.Errorf("failed to fetch applied migrations: %w", err)
+		}
+
+		for _, version := range versions {
+			migration, ok := known[version]
+			if !ok {
+				return fmt.Errorf("migration %d is not known to this build", version)
+			}
+			if err := conn.Transaction(func(transaction *gorm.DB) error {
+				if err := transaction.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return transaction.Exec("DELETE FROM "+m.table+" WHERE version = ?", migration.Version).Error
+			}); err != nil {
+				return fmt.Errorf("undoing migration %d %s failed: %w", migration.Version, migration.Name, err)
+			}
+			undone = append(undone, migration)
+		}
+
+		return nil
+	})
+
+	return undone, err
+}
+
+func (m migrator) status(db *gorm.DB) ([]State, error) {
+	var states []State
+	err := m.locked(db, func(conn *gorm.DB) error {
+		done, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			state := State{Migration: migration}
+			if row, ok := done[migration.Version]; ok {
+				state.AppliedAt = &row.AppliedAt
+				delete(done, migration.Version)
+			}
+			states = append(states, state)
+		}
+		for _, row := range done {
+			states = append(states, State{
+				Migration: Migration{Version: row.Version, Name: row.Name},
+				AppliedAt: &row.AppliedAt,
+			})
+		}
+		sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+
+		return nil
+	})
+
+	return states, err
+}
+
+func (m migrator) pending(db *gorm.DB) ([]Migration, error) {
+	if !db.Migrator().HasTable(m.table) {
+		return m.migrations, nil
+	}
+	done, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := done[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// locked runs fn on a single connection holding the advisory lock, once the
+// migrations table exists.
+func (m migrator) locked(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		// Connection does not start a new session, which the statements run on
+		// conn would otherwise share.
+		conn = conn.Session(&gorm.Session{})
+
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", m.lockKey).Error; err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", m.lockKey)
+
+		if err := conn.Exec(`CREATE TABLE IF NOT EXISTS ` + m.table + ` (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`).Error; err != nil {
+			return fmt.Errorf("failed to create %s: %w", m.table, err)
+		}
+
+		return fn(conn)
+	})
+}
+
+func (m migrator) applied(conn *gorm.DB) (map[int]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := conn.Table(m.table).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch applied migrations: %w", err)
+	}
+
+	result := make(map[int]appliedMigration, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+
+	return result, nil
+}
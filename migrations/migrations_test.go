@@ -0,0 +1,348 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joho/godotenv"
+	"github.com/pgrzankowski/dictionary-app/config"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := godotenv.Load("../.env"); err != nil && !os.IsNotExist(err) {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := All()
+	assert.NoError(t, err, "All should not return an error")
+	assert.NotEmpty(t, all, "Migrations should be embedded")
+	for ix, migration := range all {
+		assert.Equal(t, ix, migration.Version, "Versions should be numbered from 0 without gaps")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"0002_add_column.up.sql":     {Data: []byte("ALTER TABLE words ADD COLUMN language text")},
+		"0002_add_column.down.sql":   {Data: []byte("ALTER TABLE words DROP COLUMN language")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE words (word text)")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE words")},
+	})
+	assert.NoError(t, err, "load should not return an error")
+	if assert.Equal(t, 2, len(migrations), "Migrations length should match") {
+		assert.Equal(t, Migration{
+			Version: 1,
+			Name:    "create_table",
+			Up:      "CREATE TABLE words (word text)",
+			Down:    "DROP TABLE words",
+		}, migrations[0], "Migrations should be ordered by version")
+		assert.Equal(t, "add_column", migrations[1].Name, "Name should match")
+	}
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"missing down": {"0001_create_table.up.sql": {Data: []byte("CREATE TABLE words (word text)")}},
+		"invalid name": {"create_table.sql": {Data: []byte("CREATE TABLE words (word text)")}},
+		"name mismatch": {
+			"0001_create_table.up.sql": {Data: []byte("CREATE TABLE words (word text)")},
+			"0001_create.down.sql":     {Data: []byte("DROP TABLE words")},
+		},
+	} {
+		_, err := load(fsys)
+		assert.Error(t, err, fmt.Sprintf("load should reject a %s", name))
+	}
+}
+
+var testMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_table",
+		Up:      "CREATE TABLE migrations_test_words (word text)",
+		Down:    "DROP TABLE migrations_test_words",
+	},
+	{
+		Version: 2,
+		Name:    "add_column",
+		Up:      "ALTER TABLE migrations_test_words ADD COLUMN language text",
+		Down:    "ALTER TABLE migrations_test_words DROP COLUMN language",
+	},
+}
+
+// openTestDB connects to the test database, skipping the test if it is
+// unavailable, and removes what the test migrations leave behind.
+func openTestDB(t *testing.T) *gorm.DB {
+	database, err := config.TestDatabase()
+	if err == nil {
+		err = database.Validate()
+	}
+	if err != nil {
+		t.Skipf("test database unavailable: %v", err)
+	}
+	db, err := gorm.Open(postgres.Open(database.DSN()), &gorm.Config{})
+	if err != nil {
+		t.Skipf("test database unavailable: %v", err)
+	}
+
+	drop := func() {
+		if err := db.Exec("DROP TABLE IF EXISTS migrations_test_words, schema_migrations_test").Error; err != nil {
+			t.Fatalf("failed to drop tables: %v", err)
+		}
+	}
+	drop()
+	t.Cleanup(drop)
+
+	return db
+}
+
+func TestMigrator(t *testing.T) {
+	db := openTestDB(t)
+	m := migrator{table: "schema_migrations_test", lockKey: lockKey + 1, migrations: testMigrations}
+
+	pending, err := m.pending(db)
+	assert.NoError(t, err, "pending should not return an error")
+	assert.Equal(t, testMigrations, pending, "Every migration should be pending before the first run")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var applied []Migration
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := m.up(db)
+			assert.NoError(t, err, "up should not return an error")
+			mu.Lock()
+			applied = append(applied, result...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, len(applied), "Concurrent runs should apply every migration once")
+	assert.True(t, db.Migrator().HasColumn("migrations_test_words", "language"), "Migrations should be applied")
+	pending, err = m.pending(db)
+	assert.NoError(t, err, "pending should not return an error")
+	assert.Empty(t, pending, "No migration should be pending once applied")
+
+	undone, err := m.down(db, 1)
+	assert.NoError(t, err, "down should not return an error")
+	if assert.Equal(t, 1, len(undone), "down should undo a single migration") {
+		assert.Equal(t, 2, undone[0].Version, "The latest migration should be undone first")
+	}
+	assert.False(t, db.Migrator().HasColumn("migrations_test_words", "language"), "Migration should be undone")
+
+	states, err := m.status(db)
+	assert.NoError(t, err, "status should not return an error")
+	if assert.Equal(t, 2, len(states), "status should list every migration") {
+		assert.NotNil(t, states[0].AppliedAt, "First migration should be applied")
+		assert.Nil(t, states[1].AppliedAt, "Second migration should be pending")
+	}
+	pending, err = m.pending(db)
+	assert.NoError(t, err, "pending should not return an error")
+	assert.Equal(t, testMigrations[1:], pending, "Undone migration should be pending")
+
+	broken := m
+	broken.migrations = append(testMigrations[:1:1], Migration{Version: 2, Name: "broken", Up: "ALTER TABLE missing ADD COLUMN x text"})
+	_, err = broken.up(db)
+	assert.Error(t, err, "Failing migration should return an error")
+	states, _ = m.status(db)
+	assert.Nil(t, states[1].AppliedAt, "Failing migration should not be recorded")
+
+	_, err = m.down(db, 5)
+	assert.NoError(t, err, "down should not return an error")
+	assert.False(t, db.Migrator().HasTable("migrations_test_words"), "Every migration should be undone")
+
+	_, err = m.down(db, 0)
+	assert.Error(t, err, "down should need at least one step")
+}
+
+// legacyLayouts are databases as earlier versions of the server left them
+// with AutoMigrate, and what upgrading them should result in.
+var legacyLayouts = []struct {
+	name   string
+	schema string
+	check  func(t *testing.T, db *gorm.DB)
+}{
+	{
+		name: "polish to english",
+		schema: `
+			CREATE TABLE polish_words (id bigserial PRIMARY KEY, word text NOT NULL, created_at timestamptz, updated_at timestamptz);
+			CREATE UNIQUE INDEX idx_polish_word ON polish_words (word);
+			CREATE TABLE translations (
+				id bigserial PRIMARY KEY,
+				polish_word_id bigint NOT NULL,
+				english_word text NOT NULL,
+				created_at timestamptz,
+				updated_at timestamptz,
+				CONSTRAINT fk_polish_words_translations FOREIGN KEY (polish_word_id) REFERENCES polish_words(id)
+			);
+			CREATE UNIQUE INDEX idx_polish_english ON translations (polish_word_id, english_word);
+			CREATE TABLE examples (
+				id bigserial PRIMARY KEY,
+				translation_id bigint NOT NULL,
+				sentence text NOT NULL,
+				created_at timestamptz,
+				updated_at timestamptz,
+				CONSTRAINT fk_translations_examples FOREIGN KEY (translation_id) REFERENCES translations(id) ON DELETE CASCADE
+			);
+			INSERT INTO polish_words (word, created_at, updated_at) VALUES ('zamek', now(), now()), ('klucz', now(), now());
+			INSERT INTO translations (polish_word_id, english_word, created_at, updated_at) VALUES
+				(1, 'castle', now(), now()), (1, 'lock', now(), now()), (2, 'key', now(), now());
+			INSERT INTO examples (translation_id, sentence) VALUES (2, 'Zamek jest zepsuty.');
+		`,
+		check: func(t *testing.T, db *gorm.DB) {
+			var pairs []struct{ Source, Target string }
+			db.Raw(`SELECT s.language_code || ':' || s.word AS source, l.language_code || ':' || l.word AS target
+				FROM translations tr
+				JOIN lexemes s ON s.id = tr.source_lexeme_id
+				JOIN lexemes l ON l.id = tr.target_lexeme_id
+				ORDER BY tr.id`).Scan(&pairs)
+			assert.Equal(t, []struct{ Source, Target string }{
+				{"pl:zamek", "en:castle"}, {"pl:zamek", "en:lock"}, {"pl:klucz", "en:key"},
+			}, pairs, "Words should become lexemes connected by the translations")
+
+			var targets []string
+			db.Raw(`SELECT l.word FROM examples e
+				JOIN translations tr ON tr.id = e.translation_id
+				JOIN lexemes l ON l.id = tr.target_lexeme_id`).Scan(&targets)
+			assert.Equal(t, []string{"lock"}, targets, "Examples should keep their translation")
+			assert.False(t, db.Migrator().HasTable("polish_words"), "polish_words should be dropped")
+		},
+	},
+	{
+		name: "before soft deletes and accounts",
+		schema: `
+			CREATE TABLE languages (code varchar(3) PRIMARY KEY, name text NOT NULL, created_at timestamptz, updated_at timestamptz);
+			INSERT INTO languages (code, name) VALUES ('pl', 'Polish'), ('en', 'English');
+			CREATE TABLE lexemes (
+				id bigserial PRIMARY KEY,
+				language_code varchar(3) NOT NULL REFERENCES languages(code),
+				word text NOT NULL,
+				created_at timestamptz,
+				updated_at timestamptz
+			);
+			CREATE UNIQUE INDEX idx_lexeme_language_word ON lexemes (language_code, word);
+			CREATE TABLE translations (
+				id bigserial PRIMARY KEY,
+				source_lexeme_id bigint NOT NULL REFERENCES lexemes(id),
+				target_lexeme_id bigint NOT NULL REFERENCES lexemes(id),
+				created_at timestamptz,
+				updated_at timestamptz
+			);
+			CREATE UNIQUE INDEX idx_translation_source_target ON translations (source_lexeme_id, target_lexeme_id);
+			CREATE TABLE examples (
+				id bigserial PRIMARY KEY,
+				translation_id bigint NOT NULL REFERENCES translations(id) ON DELETE CASCADE,
+				sentence text NOT NULL,
+				position bigint NOT NULL DEFAULT 0,
+				created_at timestamptz,
+				updated_at timestamptz
+			);
+			CREATE TABLE reviews (
+				id bigserial PRIMARY KEY,
+				user_id bigint NOT NULL,
+				translation_id bigint NOT NULL REFERENCES translations(id) ON DELETE CASCADE,
+				ease_factor decimal NOT NULL,
+				interval bigint NOT NULL,
+				repetitions bigint NOT NULL,
+				due_at timestamptz NOT NULL,
+				last_reviewed_at timestamptz,
+				created_at timestamptz,
+				updated_at timestamptz
+			);
+			INSERT INTO lexemes (language_code, word) VALUES ('pl', 'zamek'), ('en', 'castle');
+			INSERT INTO translations (source_lexeme_id, target_lexeme_id) VALUES (1, 2);
+			INSERT INTO reviews (user_id, translation_id, ease_factor, interval, repetitions, due_at) VALUES (1, 1, 2.5, 1, 1, now());
+		`,
+		check: func(t *testing.T, db *gorm.DB) {
+			for _, table := range []string{"lexemes", "translations", "examples"} {
+				assert.True(t, db.Migrator().HasColumn(table, "deleted_at"), "%s should be soft deletable", table)
+			}
+
+			var reviews int64
+			db.Table("reviews").Count(&reviews)
+			assert.Equal(t, int64(0), reviews, "Anonymous reviews should be removed")
+
+			assert.NoError(t, db.Exec("UPDATE lexemes SET deleted_at = now()").Error, "Soft deleting should not return an error")
+			assert.NoError(t, db.Exec("INSERT INTO lexemes (language_code, word) VALUES ('pl', 'zamek')").Error,
+				"Words should be unique only among lexemes that are not deleted")
+		},
+	},
+	{
+		name: "before roles",
+		schema: `
+			CREATE TABLE users (id bigserial PRIMARY KEY, email text NOT NULL, password_hash text NOT NULL, created_at timestamptz, updated_at timestamptz);
+			CREATE UNIQUE INDEX idx_users_email ON users (email);
+			INSERT INTO users (email, password_hash) VALUES ('first@example.com', 'hash'), ('second@example.com', 'hash');
+		`,
+		check: func(t *testing.T, db *gorm.DB) {
+			var roles []string
+			db.Table("users").Order("id").Pluck("role", &roles)
+			assert.Equal(t, []string{"admin", "viewer"}, roles, "The oldest user should be made an admin")
+		},
+	},
+}
+
+// openLegacyDB connects to an empty schema of the test database, skipping the
+// test if it is unavailable.
+func openLegacyDB(t *testing.T) *gorm.DB {
+	public := openTestDB(t)
+	// Search needs its extensions in public, which migrating it installs.
+	if _, err := Up(public); err != nil {
+		t.Fatalf("failed to migrate public: %v", err)
+	}
+	drop := func() {
+		if err := public.Exec("DROP SCHEMA IF EXISTS migrations_test_legacy CASCADE").Error; err != nil {
+			t.Fatalf("failed to drop schema: %v", err)
+		}
+	}
+	drop()
+	t.Cleanup(drop)
+	if err := public.Exec("CREATE SCHEMA migrations_test_legacy").Error; err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	database, _ := config.TestDatabase()
+	database.Schema = "migrations_test_legacy"
+	db, err := gorm.Open(postgres.Open(database.DSN()), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return db
+}
+
+func TestUpgradeLegacyLayouts(t *testing.T) {
+	for _, layout := range legacyLayouts {
+		t.Run(layout.name, func(t *testing.T) {
+			db := openLegacyDB(t)
+			if err := db.Exec(layout.schema).Error; err != nil {
+				t.Fatalf("failed to create the legacy layout: %v", err)
+			}
+
+			applied, err := Up(db)
+			if !assert.NoError(t, err, "Up should upgrade the legacy layout") {
+				return
+			}
+			all, _ := All()
+			assert.Equal(t, len(all), len(applied), "Every migration should be applied")
+			layout.check(t, db)
+
+			applied, err = Up(db)
+			assert.NoError(t, err, "Up should not return an error")
+			assert.Empty(t, applied, "Nothing should be left to apply")
+		})
+	}
+}
@@ -2,28 +2,68 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
-type PolishWord struct {
+type User struct {
 	ID           uint   `gorm:"primaryKey"`
-	Word         string `gorm:"not null;uniqueIndex:idx_polish_word"`
+	Email        string `gorm:"not null;uniqueIndex"`
+	PasswordHash string `gorm:"not null"`
+	Role         string `gorm:"not null;default:viewer"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
-	Translations []Translation `gorm:"foreignKey:PolishWordID"`
 }
 
-func (PolishWord) TableName() string {
-	return "polish_words"
+func (User) TableName() string {
+	return "users"
 }
 
-type Translation struct {
-	ID           uint   `gorm:"primaryKey"`
-	PolishWordID uint   `gorm:"not null;uniqueIndex:idx_polish_english"`
-	EnglishWord  string `gorm:"not null;uniqueIndex:idx_polish_english"`
-	CreatedAt    time.Time
+type Language struct {
+	Code      string `gorm:"primaryKey;size:3"`
+	Name      string `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Language) TableName() string {
+	return "languages"
+}
+
+// DefaultLanguages are available without having to add them first. The first
+// migration adds them to the database.
+var DefaultLanguages = []Language{
+	{Code: "pl", Name: "Polish"},
+	{Code: "en", Name: "English"},
+	{Code: "de", Name: "German"},
+	{Code: "uk", Name: "Ukrainian"},
+}
+
+type Lexeme struct {
+	ID           uint      `gorm:"primaryKey;index:idx_lexemes_created_at_id,priority:2"`
+	LanguageCode string    `gorm:"not null;size:3;uniqueIndex:idx_lexeme_language_word"`
+	Word         string    `gorm:"not null;uniqueIndex:idx_lexeme_language_word,where:deleted_at IS NULL"`
+	CreatedAt    time.Time `gorm:"index:idx_lexemes_created_at_id,priority:1"`
 	UpdatedAt    time.Time
-	PolishWord   PolishWord
-	Examples     []Example `gorm:"foreignKey:TranslationID;constraint:OnDelete:CASCADE;"`
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Language     Language       `gorm:"foreignKey:LanguageCode;references:Code"`
+	Translations []Translation  `gorm:"foreignKey:SourceLexemeID"`
+}
+
+func (Lexeme) TableName() string {
+	return "lexemes"
+}
+
+type Translation struct {
+	ID             uint      `gorm:"primaryKey;index:idx_translations_created_at_id,priority:2"`
+	SourceLexemeID uint      `gorm:"not null;uniqueIndex:idx_translation_source_target"`
+	TargetLexemeID uint      `gorm:"not null;uniqueIndex:idx_translation_source_target,where:deleted_at IS NULL;index"`
+	CreatedAt      time.Time `gorm:"index:idx_translations_created_at_id,priority:1"`
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	SourceLexeme   Lexeme         `gorm:"foreignKey:SourceLexemeID"`
+	TargetLexeme   Lexeme         `gorm:"foreignKey:TargetLexemeID"`
+	Examples       []Example      `gorm:"foreignKey:TranslationID;constraint:OnDelete:CASCADE;"`
 }
 
 func (Translation) TableName() string {
@@ -34,11 +74,80 @@ type Example struct {
 	ID            uint   `gorm:"primaryKey"`
 	TranslationID uint   `gorm:"not null"`
 	Sentence      string `gorm:"not null"`
+	Position      int    `gorm:"not null;default:0"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
 	Translation   Translation
 }
 
 func (Example) TableName() string {
 	return "examples"
 }
+
+// Review is the spaced repetition progress of a user on a translation.
+type Review struct {
+	ID             uint      `gorm:"primaryKey"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_review_user_translation;index:idx_reviews_user_due,priority:1"`
+	TranslationID  uint      `gorm:"not null;uniqueIndex:idx_review_user_translation"`
+	EaseFactor     float64   `gorm:"not null"`
+	Interval       int       `gorm:"not null"`
+	Repetitions    int       `gorm:"not null"`
+	DueAt          time.Time `gorm:"not null;index:idx_reviews_user_due,priority:2"`
+	LastReviewedAt time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	User           User        `gorm:"constraint:OnDelete:CASCADE;"`
+	Translation    Translation `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (Review) TableName() string {
+	return "reviews"
+}
+
+// WordList is a user's named selection of translations from the shared
+// dictionary.
+type WordList struct {
+	ID         uint   `gorm:"primaryKey"`
+	OwnerID    uint   `gorm:"not null;uniqueIndex:idx_word_list_owner_name"`
+	Name       string `gorm:"not null;uniqueIndex:idx_word_list_owner_name"`
+	Visibility string `gorm:"not null;default:private"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Owner      User           `gorm:"constraint:OnDelete:CASCADE;"`
+	Items      []WordListItem `gorm:"foreignKey:WordListID;constraint:OnDelete:CASCADE;"`
+}
+
+func (WordList) TableName() string {
+	return "word_lists"
+}
+
+type WordListItem struct {
+	ID            uint `gorm:"primaryKey"`
+	WordListID    uint `gorm:"not null;uniqueIndex:idx_word_list_item"`
+	TranslationID uint `gorm:"not null;uniqueIndex:idx_word_list_item;index"`
+	Position      int  `gorm:"not null;default:0"`
+	CreatedAt     time.Time
+	Translation   Translation `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (WordListItem) TableName() string {
+	return "word_list_items"
+}
+
+// TranslationRevision records a change to a translation. Revisions are never
+// changed once written and outlive the translation, so neither the
+// translation nor the actor is a foreign key.
+type TranslationRevision struct {
+	ID            uint    `gorm:"primaryKey"`
+	TranslationID uint    `gorm:"not null;index"`
+	ActorID       *uint   // nil for changes made outside of a signed in request
+	Action        string  `gorm:"not null"`
+	Before        *string `gorm:"type:jsonb"`
+	After         *string `gorm:"type:jsonb"`
+	CreatedAt     time.Time
+}
+
+func (TranslationRevision) TableName() string {
+	return "translation_revisions"
+}
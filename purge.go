@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/services"
+	"gorm.io/gorm"
+)
+
+const (
+	trashPurgeInterval = time.Hour
+	// trashPurgeTimeout keeps a purge stuck on the database from holding up
+	// the ones after it.
+	trashPurgeTimeout = 5 * time.Minute
+)
+
+// purgeTrash permanently removes translations that have been in the trash for
+// longer than retention, once right away and then every interval, until ctx
+// is done.
+func purgeTrash(ctx context.Context, database *gorm.DB, retention time.Duration, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeCtx, cancel := context.WithTimeout(ctx, trashPurgeTimeout)
+		purged, err := services.PurgeTrash(database, purgeCtx, time.Now().Add(-retention))
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			log.Printf("failed to purge trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d translations from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
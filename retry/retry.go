@@ -0,0 +1,66 @@
+// Package retry repeats operations failing with transient errors, waiting
+// for an exponentially growing, jittered delay between the attempts.
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy decides how often and how long apart an operation is attempted.
+type Policy struct {
+	// Attempts is the most times the operation runs, 0 for no limit other
+	// than the context.
+	Attempts int
+	// Initial is the longest delay after the first failure. It doubles with
+	// every further failure up to Max.
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Delay returns how long to wait after the given failed attempt, counted from
+// 0. It is chosen at random up to the exponential bound, so that clients
+// failing together do not retry together.
+func (p Policy) Delay(attempt int) time.Duration {
+	bound := p.Initial
+	for i := 0; i < attempt && bound < p.Max; i++ {
+		bound *= 2
+	}
+	if bound > p.Max {
+		bound = p.Max
+	}
+	if bound <= 0 {
+		return 0
+	}
+
+	return rand.N(bound) + 1
+}
+
+// Do runs fn until it succeeds, fails with an error retryable does not accept,
+// the attempts run out or ctx is done, and returns the last error of fn.
+// onRetry, if not nil, is told about every failure that is retried.
+func Do(ctx context.Context, policy Policy, retryable func(error) bool, onRetry func(err error, delay time.Duration), fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if policy.Attempts > 0 && attempt+1 >= policy.Attempts {
+			return err
+		}
+
+		delay := policy.Delay(attempt)
+		if onRetry != nil {
+			onRetry(err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTransient = errors.New("transient")
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func TestDelay(t *testing.T) {
+	policy := Policy{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+	for attempt, bound := range []time.Duration{10, 20, 40, 50, 50, 50} {
+		for i := 0; i < 100; i++ {
+			delay := policy.Delay(attempt)
+			assert.Greater(t, delay, time.Duration(0), "Delay should be positive")
+			assert.LessOrEqual(t, delay, bound*time.Millisecond, "Delay should not exceed the bound")
+		}
+	}
+
+	assert.Equal(t, time.Duration(0), Policy{}.Delay(3), "Delay should be 0 without an initial delay")
+}
+
+func TestDo(t *testing.T) {
+	policy := Policy{Attempts: 3, Initial: time.Millisecond, Max: time.Millisecond}
+
+	calls := 0
+	err := Do(context.Background(), policy, isTransient, nil, func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	assert.NoError(t, err, "Do should succeed once fn does")
+	assert.Equal(t, 3, calls, "fn should be retried until it succeeds")
+
+	calls = 0
+	var retried []error
+	err = Do(context.Background(), policy, isTransient, func(err error, delay time.Duration) {
+		retried = append(retried, err)
+	}, func() error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient, "Do should return the last error")
+	assert.Equal(t, 3, calls, "fn should run at most Attempts times")
+	assert.Equal(t, 2, len(retried), "onRetry should be told about every retry")
+
+	calls = 0
+	permanent := errors.New("permanent")
+	err = Do(context.Background(), policy, isTransient, nil, func() error {
+		calls++
+		return permanent
+	})
+	assert.ErrorIs(t, err, permanent, "Do should return errors that are not retryable")
+	assert.Equal(t, 1, calls, "Errors that are not retryable should not be retried")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	calls = 0
+	err = Do(ctx, Policy{Initial: time.Millisecond, Max: 5 * time.Millisecond}, isTransient, nil, func() error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient, "Do should return the last error once ctx is done")
+	assert.Greater(t, calls, 1, "fn should be retried until ctx is done")
+}
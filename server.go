@@ -1,47 +1,152 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/config"
 	"github.com/pgrzankowski/dictionary-app/graph"
+	"github.com/pgrzankowski/dictionary-app/loaders"
+	"github.com/pgrzankowski/dictionary-app/metrics"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/pgrzankowski/dictionary-app/srs"
+	"github.com/pgrzankowski/dictionary-app/tracing"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/vektah/gqlparser/v2/ast"
 
 	"github.com/pgrzankowski/dictionary-app/db"
 )
 
-const defaultPort = "8080"
+// shutdownTimeout is how long requests in flight are given to finish once the
+// server is asked to stop.
+const shutdownTimeout = 30 * time.Second
 
 func main() {
-	db.ConnectGORM()
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort
+	database, err := db.ConnectGORM(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
 	}
+	service := services.New(database)
+
+	tokens := auth.NewIssuer([]byte(cfg.Auth.JWTSecret))
+
+	// ctx is cancelled once the server is asked to stop, which stops the
+	// background work along with it.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	go purgeTrash(ctx, database, cfg.Trash.Retention, trashPurgeInterval)
+	go db.ListenEvents(ctx, database)
 
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers: &graph.Resolver{
+			Service: service,
+			Clock:   srs.SystemClock{},
+			Tokens:  tokens,
+		},
+		Directives: graph.DirectiveRoot{
+			HasRole: graph.HasRole,
+		},
+	}))
+
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
 
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	srv.SetErrorPresenter(graph.ErrorPresenter)
 
 	srv.Use(extension.Introspection{})
 	srv.Use(extension.AutomaticPersistedQuery{
 		Cache: lru.New[string](100),
 	})
+	srv.Use(loaders.Extension{Service: service})
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	registry := metrics.NewRegistry(sqlDB)
+	srv.Use(metrics.NewExtension(registry))
+	srv.Use(tracing.Extension{})
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	http.Handle("/query", tracing.Middleware(auth.Middleware(database, tokens)(srv)))
+	http.Handle("/export", exportHandler(database))
+	http.HandleFunc("/healthz", healthHandler)
+	http.Handle("/readyz", readyHandler(database))
+	http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
 
-	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdown <- server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("connect to http://localhost:%s/ for GraphQL playground", cfg.Server.Port)
+	err = server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = <-shutdown
+	}
+	stop()
+	shutdownTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
 }
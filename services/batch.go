@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+)
+
+// The functions below load the relations of many objects with a single query
+// each, for the dataloaders behind the nested GraphQL fields. Keys without a
+// match are left out of the result.
+
+// LexemesByID includes lexemes in the trash, which translations in the trash
+// still point at.
+func LexemesByID(db *gorm.DB, ctx context.Context, ids []uint) (map[uint]*model.Lexeme, error) {
+	var lexemes []gormModels.Lexeme
+	if err := db.WithContext(ctx).
+		Unscoped().
+		Where("id IN ?", ids).
+		Find(&lexemes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch words: %w", err)
+	}
+
+	result := make(map[uint]*model.Lexeme, len(lexemes))
+	for _, lexeme := range lexemes {
+		result[lexeme.ID] = convertLexeme(lexeme)
+	}
+
+	return result, nil
+}
+
+func LanguagesByCode(db *gorm.DB, ctx context.Context, codes []string) (map[string]*model.Language, error) {
+	var languages []gormModels.Language
+	if err := db.WithContext(ctx).
+		Where("code IN ?", codes).
+		Find(&languages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch languages: %w", err)
+	}
+
+	result := make(map[string]*model.Language, len(languages))
+	for _, language := range languages {
+		result[language.Code] = convertLanguage(language)
+	}
+
+	return result, nil
+}
+
+// TranslationsByID includes translations in the trash, which their examples
+// still point at.
+func TranslationsByID(db *gorm.DB, ctx context.Context, ids []uint) (map[uint]*model.Translation, error) {
+	var translations []gormModels.Translation
+	if err := db.WithContext(ctx).
+		Unscoped().
+		Where("id IN ?", ids).
+		Find(&translations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+
+	result := make(map[uint]*model.Translation, len(translations))
+	for _, translation := range translations {
+		result[translation.ID] = convertTranslation(translation)
+	}
+
+	return result, nil
+}
+
+// TranslationsByLexeme lists the translations from and into each of the
+// lexemes.
+func TranslationsByLexeme(db *gorm.DB, ctx context.Context, lexemeIDs []uint) (map[uint][]*model.Translation, error) {
+	var translations []gormModels.Translation
+	if err := db.WithContext(ctx).
+		Where("source_lexeme_id IN ? OR target_lexeme_id IN ?", lexemeIDs, lexemeIDs).
+		Order("id").
+		Find(&translations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+
+	result := make(map[uint][]*model.Translation, len(lexemeIDs))
+	for _, translation := range translations {
+		converted := convertTranslation(translation)
+		result[translation.SourceLexemeID] = append(result[translation.SourceLexemeID], converted)
+		if translation.TargetLexemeID != translation.SourceLexemeID {
+			result[translation.TargetLexemeID] = append(result[translation.TargetLexemeID], converted)
+		}
+	}
+
+	return result, nil
+}
+
+// ExamplesByTranslation lists the examples of each of the translations in
+// order. Translations in the trash get the examples removed along with them.
+func ExamplesByTranslation(db *gorm.DB, ctx context.Context, translationIDs []uint) (map[uint][]*model.Example, error) {
+	var examples []gormModels.Example
+	if err := db.WithContext(ctx).
+		Unscoped().
+		Where("translation_id IN ?", translationIDs).
+		Where("examples.deleted_at IS NULL OR examples.deleted_at >= (SELECT deleted_at FROM translations WHERE translations.id = examples.translation_id)").
+		Scopes(orderExamples).
+		Find(&examples).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch examples: %w", err)
+	}
+
+	result := make(map[uint][]*model.Example, len(translationIDs))
+	for _, example := range examples {
+		result[example.TranslationID] = append(result[example.TranslationID], convertExample(example))
+	}
+
+	return result, nil
+}
+
+// RevisionsByTranslation lists the revisions of each of the translations,
+// newest first, including those of translations that no longer exist.
+func RevisionsByTranslation(db *gorm.DB, ctx context.Context, translationIDs []uint) (map[uint][]*model.TranslationRevision, error) {
+	var revisions []gormModels.TranslationRevision
+	if err := db.WithContext(ctx).
+		Where("translation_id IN ?", translationIDs).
+		Order("id DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch revisions: %w", err)
+	}
+
+	var actorIDs []uint
+	for _, revision := range revisions {
+		if revision.ActorID != nil {
+			actorIDs = append(actorIDs, *revision.ActorID)
+		}
+	}
+
+	actors := make(map[uint]*model.UserProfile, len(actorIDs))
+	if len(actorIDs) > 0 {
+		var users []gormModels.User
+		if err := db.WithContext(ctx).Find(&users, actorIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch users: %w", err)
+		}
+		for _, user := range users {
+			actors[user.ID] = convertUserProfile(user)
+		}
+	}
+
+	result := make(map[uint][]*model.TranslationRevision, len(translationIDs))
+	for _, revision := range revisions {
+		result[revision.TranslationID] = append(result[revision.TranslationID], convertRevision(revision, actors))
+	}
+
+	return result, nil
+}
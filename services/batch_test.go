@@ -0,0 +1,153 @@
+package services_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// expandedTranslation is a translation together with the relations that the
+// GraphQL layer loads through the batch functions.
+type expandedTranslation struct {
+	*model.Translation
+	EnglishWord string
+	Source      *expandedLexeme
+	Target      *expandedLexeme
+	PolishWord  *expandedLexeme
+	Examples    []*model.Example
+}
+
+type expandedLexeme struct {
+	*model.Lexeme
+	Language     *model.Language
+	Translations []*model.Translation
+}
+
+func expand(t *testing.T, translation *model.Translation) *expandedTranslation {
+	t.Helper()
+	ctx := context.Background()
+
+	lexemes, err := services.LexemesByID(testDB, ctx, []uint{translation.SourceLexemeID, translation.TargetLexemeID})
+	if err != nil {
+		t.Fatalf("failed to load lexemes: %v", err)
+	}
+	examples, err := services.ExamplesByTranslation(testDB, ctx, []uint{parseID(t, translation.ID)})
+	if err != nil {
+		t.Fatalf("failed to load examples: %v", err)
+	}
+
+	source := expandLexeme(t, lexemes[translation.SourceLexemeID])
+	target := expandLexeme(t, lexemes[translation.TargetLexemeID])
+	expanded := &expandedTranslation{
+		Translation: translation,
+		Source:      source,
+		Target:      target,
+		Examples:    examples[parseID(t, translation.ID)],
+	}
+	if english := sideIn(target, source, "en"); english != nil {
+		expanded.EnglishWord = english.Word
+	}
+	expanded.PolishWord = sideIn(source, target, "pl")
+
+	return expanded
+}
+
+// sideIn returns the first of the lexemes in language, or nil.
+func sideIn(first *expandedLexeme, second *expandedLexeme, language string) *expandedLexeme {
+	for _, lexeme := range []*expandedLexeme{first, second} {
+		if lexeme.LanguageCode == language {
+			return lexeme
+		}
+	}
+	return nil
+}
+
+func expandLexeme(t *testing.T, lexeme *model.Lexeme) *expandedLexeme {
+	t.Helper()
+	ctx := context.Background()
+
+	languages, err := services.LanguagesByCode(testDB, ctx, []string{lexeme.LanguageCode})
+	if err != nil {
+		t.Fatalf("failed to load language: %v", err)
+	}
+
+	return &expandedLexeme{
+		Lexeme:       lexeme,
+		Language:     languages[lexeme.LanguageCode],
+		Translations: translationsOf(t, lexeme.ID),
+	}
+}
+
+// translationsOf loads the translations from and into the lexeme with the
+// given id.
+func translationsOf(t *testing.T, lexemeID string) []*model.Translation {
+	t.Helper()
+
+	translations, err := services.TranslationsByLexeme(testDB, context.Background(), []uint{parseID(t, lexemeID)})
+	if err != nil {
+		t.Fatalf("failed to load translations: %v", err)
+	}
+
+	return translations[parseID(t, lexemeID)]
+}
+
+func parseID(t *testing.T, id string) uint {
+	t.Helper()
+
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		t.Fatalf("invalid id %q: %v", id, err)
+	}
+
+	return uint(intID)
+}
+
+func TestBatchFunctions(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	write, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "pisać",
+		EnglishWord: "write",
+		Examples:    []*model.NewExampleInput{{Sentence: "On lubi pisać listy."}, {Sentence: "Ona pisze książkę."}},
+	})
+	castle, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "zamek",
+		EnglishWord: "castle",
+		Examples:    []*model.NewExampleInput{{Sentence: "Zamek stoi na wzgórzu."}},
+	})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "lock"})
+
+	_, err := services.RemoveTranslation(testDB, ctx, castle.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+
+	writeID, castleID := parseID(t, write.ID), parseID(t, castle.ID)
+	translations, err := services.TranslationsByID(testDB, ctx, []uint{writeID, castleID, 999})
+	assert.NoError(t, err, "TranslationsByID should not return an error")
+	assert.Equal(t, 2, len(translations), "Missing translations should be left out")
+	assert.NotNil(t, translations[castleID].DeletedAt, "Translations in the trash should be included")
+
+	lexemes, err := services.LexemesByID(testDB, ctx, []uint{castle.SourceLexemeID, castle.TargetLexemeID})
+	assert.NoError(t, err, "LexemesByID should not return an error")
+	assert.Equal(t, "castle", lexemes[castle.TargetLexemeID].Word, "Lexemes in the trash should be included")
+
+	examples, err := services.ExamplesByTranslation(testDB, ctx, []uint{writeID, castleID})
+	assert.NoError(t, err, "ExamplesByTranslation should not return an error")
+	assert.Equal(t, []string{"On lubi pisać listy.", "Ona pisze książkę."}, exampleSentences(examples[writeID]), "Examples should be grouped in order")
+	assert.Equal(t, 1, len(examples[castleID]), "Examples removed with the translation should be listed")
+
+	byLexeme, err := services.TranslationsByLexeme(testDB, ctx, []uint{write.SourceLexemeID, write.TargetLexemeID, castle.SourceLexemeID})
+	assert.NoError(t, err, "TranslationsByLexeme should not return an error")
+	assert.Equal(t, 1, len(byLexeme[write.SourceLexemeID]), "Translations should be grouped by source")
+	assert.Equal(t, 1, len(byLexeme[write.TargetLexemeID]), "Translations should be grouped by target")
+	assert.Equal(t, 1, len(byLexeme[castle.SourceLexemeID]), "Translations in the trash should be left out")
+
+	languages, err := services.LanguagesByCode(testDB, ctx, []string{"pl", "xx"})
+	assert.NoError(t, err, "LanguagesByCode should not return an error")
+	assert.Equal(t, 1, len(languages), "Unknown languages should be left out")
+}
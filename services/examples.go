@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddExample inserts a sentence at position, or appends it when position is
+// nil. Later examples are shifted to make room.
+func AddExample(db *gorm.DB, ctx context.Context, translationID string, sentence string, position *int32) (*model.Example, error) {
+	intID, err := strconv.Atoi(translationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	sentence, err = checkSentence(sentence)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	examples, err := lockExamples(transaction, uint(intID))
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	before, err := loadSnapshot(transaction, uint(intID))
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	index := len(examples)
+	if position != nil {
+		if *position < 0 {
+			transaction.Rollback()
+			return nil, fmt.Errorf("position cannot be negative")
+		}
+		index = min(int(*position), len(examples))
+	}
+
+	example := gormModels.Example{
+		TranslationID: uint(intID),
+		Sentence:      sentence,
+		Position:      index,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := transaction.Create(&example).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to create example: %w", err)
+	}
+
+	reordered := append(examples[:index:index], append([]gormModels.Example{example}, examples[index:]...)...)
+	if err := savePositions(transaction, reordered); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := recordRevision(transaction, revisionUpdate, uint(intID), before); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+	publish(db, events.TranslationUpdated, example.TranslationID)
+
+	return findExample(db.WithContext(ctx), example.ID)
+}
+
+func UpdateExample(db *gorm.DB, ctx context.Context, id string, sentence string) (*model.Example, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	sentence, err = checkSentence(sentence)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	var example gormModels.Example
+	if err := transaction.First(&example, intID).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch example: %w", err)
+	}
+
+	if _, err := lockExamples(transaction, example.TranslationID); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	before, err := loadSnapshot(transaction, example.TranslationID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := transaction.
+		Model(&example).
+		Updates(map[string]interface{}{"sentence": sentence, "updated_at": time.Now()}).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to update example: %w", err)
+	}
+
+	if err := recordRevision(transaction, revisionUpdate, example.TranslationID, before); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+	publish(db, events.TranslationUpdated, example.TranslationID)
+
+	return findExample(db.WithContext(ctx), uint(intID))
+}
+
+func RemoveExample(db *gorm.DB, ctx context.Context, id string) (bool, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid id format: %w", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return false, transaction.Error
+	}
+
+	var example gormModels.Example
+	if err := transaction.First(&example, intID).Error; err != nil {
+		transaction.Rollback()
+		return false, fmt.Errorf("failed to fetch example: %w", err)
+	}
+
+	examples, err := lockExamples(transaction, example.TranslationID)
+	if err != nil {
+		transaction.Rollback()
+		return false, err
+	}
+
+	before, err := loadSnapshot(transaction, example.TranslationID)
+	if err != nil {
+		transaction.Rollback()
+		return false, err
+	}
+
+	if err := transaction.Delete(&example).Error; err != nil {
+		transaction.Rollback()
+		return false, fmt.Errorf("failed to delete example: %w", err)
+	}
+
+	remaining := make([]gormModels.Example, 0, len(examples))
+	for _, ex := range examples {
+		if ex.ID != example.ID {
+			remaining = append(remaining, ex)
+		}
+	}
+	if err := savePositions(transaction, remaining); err != nil {
+		transaction.Rollback()
+		return false, err
+	}
+
+	if err := recordRevision(transaction, revisionUpdate, example.TranslationID, before); err != nil {
+		transaction.Rollback()
+		return false, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	publish(db, events.TranslationUpdated, example.TranslationID)
+
+	return true, nil
+}
+
+// ReorderExamples puts the examples of a translation in the order given by
+// exampleIDs, which must list every example of the translation exactly once.
+func ReorderExamples(db *gorm.DB, ctx context.Context, translationID string, exampleIDs []string) ([]*model.Example, error) {
+	intID, err := strconv.Atoi(translationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	examples, err := lockExamples(transaction, uint(intID))
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	before, err := loadSnapshot(transaction, uint(intID))
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	byID := make(map[string]gormModels.Example, len(examples))
+	for _, example := range examples {
+		byID[strconv.Itoa(int(example.ID))] = example
+	}
+
+	if len(exampleIDs) != len(examples) {
+		transaction.Rollback()
+		return nil, fmt.Errorf("expected %d example ids, got %d", len(examples), len(exampleIDs))
+	}
+
+	reordered := make([]gormModels.Example, 0, len(exampleIDs))
+	for _, id := range exampleIDs {
+		example, ok := byID[id]
+		if !ok {
+			transaction.Rollback()
+			return nil, fmt.Errorf("example %s does not belong to translation %s or is listed twice", id, translationID)
+		}
+		delete(byID, id)
+		reordered = append(reordered, example)
+	}
+
+	if err := savePositions(transaction, reordered); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := recordRevision(transaction, revisionUpdate, uint(intID), before); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+	publish(db, events.TranslationUpdated, uint(intID))
+
+	result, err := ExamplesByTranslation(db, ctx, []uint{uint(intID)})
+	if err != nil {
+		return nil, err
+	}
+
+	return result[uint(intID)], nil
+}
+
+// patchExamples removes the examples listed in removeIDs from a translation
+// and appends the new ones after the remaining examples. The translation row
+// is expected to be locked by the caller.
+func patchExamples(transaction *gorm.DB, translationID uint, removeIDs []string, add []*model.NewExampleInput) error {
+	if len(removeIDs) == 0 && len(add) == 0 {
+		return nil
+	}
+	sentences, err := checkSentences(add)
+	if err != nil {
+		return err
+	}
+
+	var examples []gormModels.Example
+	if err := transaction.
+		Where("translation_id = ?", translationID).
+		Scopes(orderExamples).
+		Find(&examples).Error; err != nil {
+		return fmt.Errorf("failed to fetch examples: %w", err)
+	}
+
+	toRemove := make(map[string]bool, len(removeIDs))
+	for _, id := range removeIDs {
+		toRemove[id] = true
+	}
+
+	var removed []uint
+	kept := make([]gormModels.Example, 0, len(examples)+len(add))
+	for _, example := range examples {
+		id := strconv.Itoa(int(example.ID))
+		if toRemove[id] {
+			removed = append(removed, example.ID)
+			delete(toRemove, id)
+			continue
+		}
+		kept = append(kept, example)
+	}
+	for id := range toRemove {
+		return fmt.Errorf("example %s does not belong to translation %d", id, translationID)
+	}
+
+	if len(removed) > 0 {
+		if err := transaction.Delete(&gormModels.Example{}, removed).Error; err != nil {
+			return fmt.Errorf("failed to delete examples: %w", err)
+		}
+	}
+
+	for _, sentence := range sentences {
+		example := gormModels.Example{
+			Sentence:      sentence,
+			TranslationID: translationID,
+			Position:      len(kept),
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if err := transaction.Create(&example).Error; err != nil {
+			return fmt.Errorf("failed to create example: %w", err)
+		}
+		kept = append(kept, example)
+	}
+
+	return savePositions(transaction, kept)
+}
+
+// checkSentence trims an example sentence, which cannot be empty.
+func checkSentence(sentence string) (string, error) {
+	sentence = strings.TrimSpace(sentence)
+	if sentence == "" {
+		return "", fmt.Errorf("example sentence cannot be empty")
+	}
+
+	return sentence, nil
+}
+
+// checkSentences checks the sentences of new examples like checkSentence.
+func checkSentences(examples []*model.NewExampleInput) ([]string, error) {
+	sentences := make([]string, 0, len(examples))
+	for _, example := range examples {
+		sentence, err := checkSentence(example.Sentence)
+		if err != nil {
+			return nil, err
+		}
+		sentences = append(sentences, sentence)
+	}
+
+	return sentences, nil
+}
+
+// lockExamples locks the translation row, so that concurrent edits of its
+// examples are serialized, and returns the examples in their current order.
+func lockExamples(transaction *gorm.DB, translationID uint) ([]gormModels.Example, error) {
+	var translation gormModels.Translation
+	if err := transaction.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&translation, translationID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch translation: %w", err)
+	}
+
+	var examples []gormModels.Example
+	if err := transaction.
+		Where("translation_id = ?", translationID).
+		Scopes(orderExamples).
+		Find(&examples).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch examples: %w", err)
+	}
+
+	return examples, nil
+}
+
+// savePositions numbers examples consecutively in slice order, only writing
+// the rows whose position actually changed.
+func savePositions(transaction *gorm.DB, examples []gormModels.Example) error {
+	for position, example := range examples {
+		if example.Position == position {
+			continue
+		}
+		if err := transaction.
+			Model(&gormModels.Example{}).
+			Where("id = ?", example.ID).
+			Update("position", position).Error; err != nil {
+			return fmt.Errorf("failed to update example position: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func findExample(db *gorm.DB, id uint) (*model.Example, error) {
+	var example gormModels.Example
+	if err := db.First(&example, id).Error; err != nil {
+		return nil, err
+	}
+
+	return convertExample(example), nil
+}
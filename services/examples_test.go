@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func exampleSentences(examples []*model.Example) []string {
+	var sentences []string
+	for _, example := range examples {
+		sentences = append(sentences, example.Sentence)
+	}
+	return sentences
+}
+
+func TestAddExample(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	translation, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "pisać",
+		EnglishWord: "write",
+		Examples: []*model.NewExampleInput{
+			{Sentence: "On lubi pisać listy."},
+		},
+	})
+
+	appended, err := services.AddExample(testDB, ctx, translation.ID, "Ona pisze książkę.", nil)
+	assert.NoError(t, err, "AddExample should not return an error")
+	assert.Equal(t, int32(1), appended.Position, "Example should be appended")
+	assert.Equal(t, parseID(t, translation.ID), appended.TranslationID, "Translation ID should match")
+
+	position := int32(0)
+	inserted, err := services.AddExample(testDB, ctx, translation.ID, "Piszę kodem.", &position)
+	assert.NoError(t, err, "AddExample should not return an error")
+	assert.Equal(t, int32(0), inserted.Position, "Example should be inserted first")
+
+	updated, err := services.Translation(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "Translation should not return an error")
+	assert.Equal(t, []string{"Piszę kodem.", "On lubi pisać listy.", "Ona pisze książkę."}, exampleSentences(expand(t, updated).Examples), "Examples should be ordered by position")
+}
+
+func TestUpdateExample(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	translation, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "pisać",
+		EnglishWord: "write",
+		Examples: []*model.NewExampleInput{
+			{Sentence: "On lubi pisać listy."},
+		},
+	})
+
+	updated, err := services.UpdateExample(testDB, ctx, expand(t, translation).Examples[0].ID, "Ona lubi pisać listy.")
+	assert.NoError(t, err, "UpdateExample should not return an error")
+	assert.Equal(t, "Ona lubi pisać listy.", updated.Sentence, "Sentence should match")
+
+	_, err = services.UpdateExample(testDB, ctx, "999", "Nic.")
+	assert.Error(t, err, "Updating a non existing example should return an error")
+}
+
+func TestRemoveExample(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	translation, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "pić",
+		EnglishWord: "drink",
+		Examples: []*model.NewExampleInput{
+			{Sentence: "On lubi pić wodę."},
+			{Sentence: "Lubi też pić kawę."},
+			{Sentence: "Nie lubi pić herbaty."},
+		},
+	})
+
+	removed, err := services.RemoveExample(testDB, ctx, expand(t, translation).Examples[0].ID)
+	assert.NoError(t, err, "RemoveExample should not return an error")
+	assert.True(t, removed, "removed should be true")
+
+	examples := expand(t, translation).Examples
+	assert.Equal(t, 2, len(examples), "One example should be left out")
+	assert.Equal(t, int32(0), examples[0].Position, "Positions should be compacted")
+	assert.Equal(t, int32(1), examples[1].Position, "Positions should be compacted")
+}
+
+func TestReorderExamples(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	translation, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "pić",
+		EnglishWord: "drink",
+		Examples: []*model.NewExampleInput{
+			{Sentence: "On lubi pić wodę."},
+			{Sentence: "Lubi też pić kawę."},
+		},
+	})
+
+	examples := expand(t, translation).Examples
+	reordered, err := services.ReorderExamples(testDB, ctx, translation.ID, []string{examples[1].ID, examples[0].ID})
+	assert.NoError(t, err, "ReorderExamples should not return an error")
+	assert.Equal(t, []string{"Lubi też pić kawę.", "On lubi pić wodę."}, exampleSentences(reordered), "Examples should be reordered")
+
+	_, err = services.ReorderExamples(testDB, ctx, translation.ID, []string{examples[0].ID, examples[0].ID})
+	assert.Error(t, err, "Duplicated ids should return an error")
+	_, err = services.ReorderExamples(testDB, ctx, translation.ID, []string{examples[0].ID})
+	assert.Error(t, err, "Missing ids should return an error")
+}
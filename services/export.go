@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is the number of translations loaded from the database at a
+// time while exporting.
+const exportBatchSize = 500
+
+type ExportFormat string
+
+const (
+	ExportFormatJSONL ExportFormat = "jsonl"
+	ExportFormatCSV   ExportFormat = "csv"
+	// ExportFormatAnki is a tab-separated file that Anki imports as notes with
+	// the source word on the front and the target word and examples on the
+	// back.
+	ExportFormatAnki ExportFormat = "anki"
+)
+
+func (format ExportFormat) IsValid() bool {
+	switch format {
+	case ExportFormatJSONL, ExportFormatCSV, ExportFormatAnki:
+		return true
+	}
+	return false
+}
+
+func (format ExportFormat) ContentType() string {
+	switch format {
+	case ExportFormatJSONL:
+		return "application/jsonl; charset=utf-8"
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/tab-separated-values; charset=utf-8"
+	}
+}
+
+func (format ExportFormat) Extension() string {
+	switch format {
+	case ExportFormatJSONL:
+		return "jsonl"
+	case ExportFormatCSV:
+		return "csv"
+	default:
+		return "txt"
+	}
+}
+
+// ExportFilter limits which translations are exported. Zero fields are
+// ignored.
+type ExportFilter struct {
+	SourceLanguage string
+	TargetLanguage string
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+}
+
+// ExportTranslations writes the translations matching filter to w in the
+// given format. Translations are read in batches, so the dictionary is never
+// held in memory as a whole.
+func ExportTranslations(db *gorm.DB, ctx context.Context, w io.Writer, format ExportFormat, filter ExportFilter) error {
+	var write func(translationSnapshot) error
+	var flush func() error
+	switch format {
+	case ExportFormatJSONL:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		write = func(translation translationSnapshot) error {
+			return encoder.Encode(translation)
+		}
+		flush = func() error { return nil }
+	case ExportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"id", "source_language", "source", "target_language", "target", "examples", "created_at"}); err != nil {
+			return err
+		}
+		write = func(translation translationSnapshot) error {
+			return writer.Write([]string{
+				strconv.Itoa(int(translation.ID)),
+				translation.Source.Language,
+				translation.Source.Word,
+				translation.Target.Language,
+				translation.Target.Word,
+				strings.Join(translation.Examples, "\n"),
+				translation.CreatedAt.Format(time.RFC3339),
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case ExportFormatAnki:
+		if _, err := io.WriteString(w, "#separator:tab\n#html:true\n#columns:Front\tBack\n"); err != nil {
+			return err
+		}
+		write = func(translation translationSnapshot) error {
+			back := []string{"<b>" + ankiField(translation.Target.Word) + "</b>"}
+			for _, example := range translation.Examples {
+				back = append(back, "<i>"+ankiField(example)+"</i>")
+			}
+			_, err := fmt.Fprintf(w, "%s\t%s\n", ankiField(translation.Source.Word), strings.Join(back, "<br>"))
+			return err
+		}
+		flush = func() error { return nil }
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	query := db.WithContext(ctx).Model(&gormModels.Translation{}).Scopes(preloadSnapshot)
+	if filter.SourceLanguage != "" {
+		query = query.Where("source_lexeme_id IN (SELECT id FROM lexemes WHERE language_code = ?)", filter.SourceLanguage)
+	}
+	if filter.TargetLanguage != "" {
+		query = query.Where("target_lexeme_id IN (SELECT id FROM lexemes WHERE language_code = ?)", filter.TargetLanguage)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+
+	var batch []gormModels.Translation
+	result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, translation := range batch {
+			if err := write(snapshotTranslation(translation)); err != nil {
+				return err
+			}
+		}
+		return flush()
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to export translations: %w", result.Error)
+	}
+
+	return flush()
+}
+
+// ankiField escapes text for an HTML field of a tab-separated Anki file, in
+// which tabs and newlines would start a new field or note.
+func ankiField(text string) string {
+	text = html.EscapeString(text)
+	return strings.NewReplacer("\t", " ", "\r\n", "<br>", "\n", "<br>").Replace(text)
+}
@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTranslations(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "pisać",
+		EnglishWord: "write",
+		Examples:    []*model.NewExampleInput{{Sentence: "Piszę list."}, {Sentence: "Piszę <b>wiersz</b>."}},
+	})
+	services.AddTranslation(testDB, ctx, model.TranslationInput{
+		Source: &model.LexemeInput{Language: "de", Word: "schreiben"},
+		Target: &model.LexemeInput{Language: "en", Word: "write"},
+	})
+
+	var out bytes.Buffer
+	err := services.ExportTranslations(testDB, ctx, &out, services.ExportFormatJSONL, services.ExportFilter{})
+	assert.NoError(t, err, "ExportTranslations should not return an error")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 2, len(lines), "Every translation should be exported")
+
+	var exported struct {
+		Source   struct{ Language, Word string }
+		Examples []string
+	}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &exported), "Lines should be valid JSON")
+	assert.Equal(t, "pisać", exported.Source.Word, "Source word should match")
+	assert.Equal(t, 2, len(exported.Examples), "Examples should be exported")
+
+	out.Reset()
+	err = services.ExportTranslations(testDB, ctx, &out, services.ExportFormatCSV, services.ExportFilter{SourceLanguage: "de"})
+	assert.NoError(t, err, "ExportTranslations should not return an error")
+	assert.Equal(t, 2, len(strings.Split(strings.TrimSpace(out.String()), "\n")), "Only the header and German translations should be exported")
+
+	out.Reset()
+	err = services.ExportTranslations(testDB, ctx, &out, services.ExportFormatAnki, services.ExportFilter{SourceLanguage: "pl"})
+	assert.NoError(t, err, "ExportTranslations should not return an error")
+	assert.Contains(t, out.String(), "pisać\t<b>write</b><br><i>Piszę list.</i><br><i>Piszę &lt;b&gt;wiersz&lt;/b&gt;.</i>\n", "Examples should be on the back of the card")
+
+	err = services.ExportTranslations(testDB, ctx, &out, services.ExportFormat("apkg"), services.ExportFilter{})
+	assert.Error(t, err, "Unsupported format should return an error")
+}
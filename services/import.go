@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"gorm.io/gorm"
+)
+
+// importSavepoint is rolled back to when a single row fails, so that the rows
+// before it survive in BEST_EFFORT mode.
+const importSavepoint = "import_row"
+
+// ImportTranslations reads Polish→English translations from a CSV or TSV file
+// with one translation per row: the Polish word, the English word and any
+// number of example sentences. A header row starting with "polish" is
+// skipped. Rows are added like CreateTranslation adds them, rows repeating an
+// existing translation are reported as duplicates.
+func ImportTranslations(db *gorm.DB, ctx context.Context, file io.Reader, format *model.ImportFormat, dryRun *bool, mode *model.ImportMode) (*model.ImportReport, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if format != nil && *format == model.ImportFormatTsv {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+
+	importMode := model.ImportModeAllOrNothing
+	if mode != nil {
+		importMode = *mode
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	report := &model.ImportReport{Rows: []*model.ImportRow{}}
+	var created []uint
+	// first stays set until a record parses, so that the header is looked
+	// for on the first line that is not malformed.
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Invalid++
+			report.Rows = append(report.Rows, &model.ImportRow{
+				Line:    int32(parseErr.StartLine),
+				Status:  model.ImportRowStatusInvalid,
+				Message: stringPtr(parseErr.Err.Error()),
+			})
+			continue
+		} else if err != nil {
+			transaction.Rollback()
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		isHeader := first && strings.HasPrefix(strings.ToLower(strings.TrimSpace(record[0])), "polish")
+		first = false
+		if isHeader {
+			continue
+		}
+
+		row, translationID, err := importRow(transaction, int32(line), record)
+		if err != nil {
+			transaction.Rollback()
+			return nil, fmt.Errorf("failed to import line %d: %w", line, err)
+		}
+		switch row.Status {
+		case model.ImportRowStatusCreated:
+			report.Created++
+			created = append(created, translationID)
+		case model.ImportRowStatusDuplicate:
+			report.Duplicates++
+		case model.ImportRowStatusInvalid:
+			report.Invalid++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	if (dryRun != nil && *dryRun) || (importMode == model.ImportModeAllOrNothing && report.Invalid > 0) {
+		if err := transaction.Rollback().Error; err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	report.Committed = true
+	publish(db, events.TranslationCreated, created...)
+
+	return report, nil
+}
+
+// importRow adds the translation described by record, undoing its partial
+// changes if that fails. The id of the translation is returned along with the
+// row once it is created. An error is only returned when the savepoint of the
+// row cannot be set or rolled back to, which leaves the transaction unusable
+// for the rows after it.
+func importRow(transaction *gorm.DB, line int32, record []string) (*model.ImportRow, uint, error) {
+	row := &model.ImportRow{Line: line}
+	if len(record) < 2 {
+		row.Status = model.ImportRowStatusInvalid
+		row.Message = stringPtr("expected a Polish and an English word")
+		return row, 0, nil
+	}
+
+	input := model.NewTranslationInput{
+		PolishWord:  strings.TrimSpace(record[0]),
+		EnglishWord: strings.TrimSpace(record[1]),
+	}
+	row.PolishWord = &input.PolishWord
+	row.EnglishWord = &input.EnglishWord
+	for _, sentence := range record[2:] {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			input.Examples = append(input.Examples, &model.NewExampleInput{Sentence: sentence})
+		}
+	}
+
+	if err := transaction.SavePoint(importSavepoint).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to set savepoint: %w", err)
+	}
+
+	translation, err := addTranslation(transaction, polishEnglishInput(input))
+	if err != nil {
+		if rollbackErr := transaction.RollbackTo(importSavepoint).Error; rollbackErr != nil {
+			return nil, 0, fmt.Errorf("failed to roll back to savepoint: %w", rollbackErr)
+		}
+		row.Status = model.ImportRowStatusInvalid
+		if errors.Is(err, ErrConflict) {
+			row.Status = model.ImportRowStatusDuplicate
+		}
+		row.Message = stringPtr(err.Error())
+		return row, 0, nil
+	}
+
+	row.Status = model.ImportRowStatusCreated
+	return row, translation.ID, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
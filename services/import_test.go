@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+const importCSV = `polish,english,example
+pisać,write,"Lubię pisać, kiedy pada."
+pić,drink
+pisać,write
+,empty
+jeść
+`
+
+func TestImportTranslationsAllOrNothing(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	report, err := services.ImportTranslations(testDB, ctx, strings.NewReader(importCSV), nil, nil, nil)
+	assert.NoError(t, err, "ImportTranslations should not return an error")
+	assert.Equal(t, int32(2), report.Created, "Created count should match")
+	assert.Equal(t, int32(1), report.Duplicates, "Duplicate count should match")
+	assert.Equal(t, int32(2), report.Invalid, "Invalid count should match")
+	assert.False(t, report.Committed, "Nothing should be written when a row is invalid")
+
+	assert.Equal(t, 5, len(report.Rows), "Every row but the header should be reported")
+	assert.Equal(t, int32(4), report.Rows[2].Line, "Line numbers should count the header")
+	assert.Equal(t, model.ImportRowStatusDuplicate, report.Rows[2].Status, "Repeated row should be a duplicate")
+	assert.Equal(t, model.ImportRowStatusInvalid, report.Rows[3].Status, "Empty word should be invalid")
+	assert.Equal(t, model.ImportRowStatusInvalid, report.Rows[4].Status, "Missing column should be invalid")
+
+	translations, _ := services.Translations(testDB, ctx)
+	assert.Empty(t, translations, "No translation should be created")
+}
+
+func TestImportTranslationsBestEffort(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pić", EnglishWord: "drink"})
+
+	format := model.ImportFormatTsv
+	mode := model.ImportModeBestEffort
+	file := "pisać\twrite\tPiszę list.\tPiszę \"książkę\".\npić\tdrink\n\tempty\n"
+	report, err := services.ImportTranslations(testDB, ctx, strings.NewReader(file), &format, nil, &mode)
+	assert.NoError(t, err, "ImportTranslations should not return an error")
+	assert.Equal(t, int32(1), report.Created, "Created count should match")
+	assert.Equal(t, int32(1), report.Duplicates, "Existing translation should be a duplicate")
+	assert.Equal(t, int32(1), report.Invalid, "Invalid count should match")
+	assert.True(t, report.Committed, "Valid rows should be written")
+
+	lexeme, err := services.LexemeByWord(testDB, ctx, "pl", "pisać")
+	assert.NoError(t, err, "Imported word should exist")
+	examples := expand(t, translationsOf(t, lexeme.ID)[0]).Examples
+	assert.Equal(t, 2, len(examples), "Examples should be imported")
+	assert.Equal(t, `Piszę "książkę".`, examples[1].Sentence, "Quotes should be kept in TSV")
+}
+
+func TestImportTranslationsDryRun(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	dryRun := true
+	report, err := services.ImportTranslations(testDB, ctx, strings.NewReader("pisać,write\npić,drink\n"), nil, &dryRun, nil)
+	assert.NoError(t, err, "ImportTranslations should not return an error")
+	assert.Equal(t, int32(2), report.Created, "Created count should match")
+	assert.False(t, report.Committed, "Dry run should not write anything")
+
+	translations, _ := services.Translations(testDB, ctx)
+	assert.Empty(t, translations, "No translation should be created")
+}
+
+func TestImportTranslationsMalformedFirstLine(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	dryRun := true
+	file := "pol\"ish,english\npolish,english\npisać,write\n"
+	report, err := services.ImportTranslations(testDB, ctx, strings.NewReader(file), nil, &dryRun, nil)
+	assert.NoError(t, err, "ImportTranslations should not return an error")
+	assert.Equal(t, int32(1), report.Invalid, "Malformed line should be invalid")
+	assert.Equal(t, int32(1), report.Created, "Created count should match")
+	if assert.Equal(t, 2, len(report.Rows), "Header after a malformed line should be skipped") {
+		assert.Equal(t, int32(1), report.Rows[0].Line, "Malformed line should be reported")
+		assert.Equal(t, int32(3), report.Rows[1].Line, "Line numbers should count the header")
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+)
+
+// languageCodePattern accepts ISO 639-1 and ISO 639-3 codes.
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+func Languages(db *gorm.DB, ctx context.Context) ([]*model.Language, error) {
+	var languages []gormModels.Language
+	if err := db.WithContext(ctx).Order("code").Find(&languages).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Language, 0, len(languages))
+	for _, language := range languages {
+		result = append(result, convertLanguage(language))
+	}
+
+	return result, nil
+}
+
+func AddLanguage(db *gorm.DB, ctx context.Context, code string, name string) (*model.Language, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !languageCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("invalid language code '%s', expected an ISO 639 code", code)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("language name cannot be empty")
+	}
+
+	language := gormModels.Language{Code: code, Name: name}
+	if err := db.WithContext(ctx).Create(&language).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: language '%s' already exists", ErrConflict, code)
+		}
+		return nil, fmt.Errorf("failed to create language: %w", err)
+	}
+
+	return convertLanguage(language), nil
+}
+
+func checkLanguage(db *gorm.DB, code string) error {
+	var count int64
+	if err := db.Model(&gormModels.Language{}).Where("code = ?", code).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to fetch language: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("unsupported language '%s'", code)
+	}
+
+	return nil
+}
+
+func convertLanguage(language gormModels.Language) *model.Language {
+	return &model.Language{
+		Code: language.Code,
+		Name: language.Name,
+	}
+}
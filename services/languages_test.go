@@ -0,0 +1,36 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddLanguage(t *testing.T) {
+
+	setupTestDB(t)
+	t.Cleanup(func() {
+		testDB.Exec("DELETE FROM languages WHERE code = 'fra'")
+	})
+
+	ctx := context.Background()
+	language, err := services.AddLanguage(testDB, ctx, " FRA ", "French")
+	assert.NoError(t, err, "AddLanguage should not return an error")
+	assert.Equal(t, "fra", language.Code, "Code should be normalized")
+
+	_, err = services.AddLanguage(testDB, ctx, "fra", "French")
+	assert.ErrorIs(t, err, services.ErrConflict, "Duplicate language should conflict")
+
+	_, err = services.AddLanguage(testDB, ctx, "french", "French")
+	assert.Error(t, err, "Invalid code should return an error")
+
+	languages, err := services.Languages(testDB, ctx)
+	assert.NoError(t, err, "Languages should not return an error")
+	codes := make([]string, 0, len(languages))
+	for _, language := range languages {
+		codes = append(codes, language.Code)
+	}
+	assert.Subset(t, codes, []string{"de", "en", "fra", "pl", "uk"}, "Default and added languages should be listed")
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func Lexeme(db *gorm.DB, ctx context.Context, id string) (*model.Lexeme, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	return findLexeme(db.WithContext(ctx), "id = ?", intID)
+}
+
+func LexemeByWord(db *gorm.DB, ctx context.Context, language string, word string) (*model.Lexeme, error) {
+	return findLexeme(db.WithContext(ctx), "language_code = ? AND word = ?", language, strings.TrimSpace(word))
+}
+
+func Lexemes(db *gorm.DB, ctx context.Context, filter *model.LexemeFilter, page *model.PageInput) (*model.LexemeConnection, error) {
+	lexemes, pageInfo, err := findLexemes(db.WithContext(ctx), filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*model.LexemeEdge, 0, len(lexemes))
+	for _, lexeme := range lexemes {
+		edges = append(edges, &model.LexemeEdge{
+			Cursor: encodeCursor(lexemeCursor(lexeme)),
+			Node:   convertLexeme(lexeme),
+		})
+	}
+
+	return &model.LexemeConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func RenameLexeme(db *gorm.DB, ctx context.Context, id string, word string) (*model.Lexeme, error) {
+	lexeme, err := renameLexeme(db.WithContext(ctx), id, "", word)
+	if err != nil {
+		return nil, err
+	}
+
+	return findLexeme(db.WithContext(ctx), "id = ?", lexeme.ID)
+}
+
+// DeleteLexeme removes a lexeme. Lexemes that are still translated are only
+// removed when cascade is set, in which case their translations and examples
+// go with them, as do lexemes left without any translation.
+func DeleteLexeme(db *gorm.DB, ctx context.Context, id string, cascade bool) (bool, error) {
+	return deleteLexeme(db.WithContext(ctx), id, "", cascade)
+}
+
+func findLexeme(db *gorm.DB, query string, args ...interface{}) (*model.Lexeme, error) {
+	var lexeme gormModels.Lexeme
+	if err := db.Where(query, args...).First(&lexeme).Error; err != nil {
+		return nil, err
+	}
+
+	return convertLexeme(lexeme), nil
+}
+
+func findLexemes(db *gorm.DB, filter *model.LexemeFilter, page *model.PageInput) ([]gormModels.Lexeme, *model.PageInfo, error) {
+	base := db.Model(&gormModels.Lexeme{})
+	if filter != nil {
+		if filter.Language != nil {
+			base = base.Where("lexemes.language_code = ?", *filter.Language)
+		}
+		if filter.Prefix != nil {
+			base = base.Where("f_unaccent(lower(lexemes.word)) LIKE f_unaccent(lower(?))",
+				searchPattern(model.SearchModePrefix, *filter.Prefix))
+		}
+		if filter.Contains != nil {
+			base = base.Where("f_unaccent(lower(lexemes.word)) LIKE f_unaccent(lower(?))",
+				searchPattern(model.SearchModeSubstring, *filter.Contains))
+		}
+		if filter.HasTranslations != nil {
+			exists := "EXISTS (SELECT 1 FROM translations WHERE (translations.source_lexeme_id = lexemes.id OR translations.target_lexeme_id = lexemes.id) AND translations.deleted_at IS NULL)"
+			if !*filter.HasTranslations {
+				exists = "NOT " + exists
+			}
+			base = base.Where(exists)
+		}
+	}
+
+	return paginate(
+		base,
+		"lexemes",
+		pageArgsFromInput(page),
+		lexemeCursor,
+	)
+}
+
+// renameLexeme changes the spelling of a lexeme. When language is not empty
+// the lexeme must be in that language.
+func renameLexeme(db *gorm.DB, id string, language string, word string) (*gormModels.Lexeme, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, fmt.Errorf("word cannot be empty")
+	}
+
+	transaction := db.Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	lexeme, err := lockLexeme(transaction, uint(intID), language)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	var existing gormModels.Lexeme
+	if err := transaction.
+		Where("language_code = ? AND word = ? AND id <> ?", lexeme.LanguageCode, word, lexeme.ID).
+		First(&existing).Error; err == nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("%w: %s word '%s' already exists", ErrConflict, lexeme.LanguageCode, word)
+	} else if err != gorm.ErrRecordNotFound {
+		transaction.Rollback()
+		return nil, fmt.Errorf("error checking for existing word: %w", err)
+	}
+
+	var translationIDs []uint
+	if err := transaction.
+		Model(&gormModels.Translation{}).
+		Where("source_lexeme_id = ? OR target_lexeme_id = ?", lexeme.ID, lexeme.ID).
+		Order("id").
+		Pluck("id", &translationIDs).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+	befores := make([]*translationSnapshot, len(translationIDs))
+	for i, translationID := range translationIDs {
+		if befores[i], err = loadSnapshot(transaction, translationID); err != nil {
+			transaction.Rollback()
+			return nil, err
+		}
+	}
+
+	lexeme.Word = word
+	lexeme.UpdatedAt = time.Now()
+	if err := transaction.Omit(clause.Associations).Save(&lexeme).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to rename word: %w", err)
+	}
+
+	// Every translation of the lexeme reads differently afterwards, so the
+	// rename is in the history of each of them.
+	for i, translationID := range translationIDs {
+		if err := recordRevision(transaction, revisionUpdate, translationID, befores[i]); err != nil {
+			transaction.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+	publish(db, events.TranslationUpdated, translationIDs...)
+
+	return &lexeme, nil
+}
+
+func deleteLexeme(db *gorm.DB, id string, language string, cascade bool) (bool, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid id format: %w", err)
+	}
+
+	transaction := db.Begin()
+	if transaction.Error != nil {
+		return false, transaction.Error
+	}
+
+	lexeme, err := lockLexeme(transaction, uint(intID), language)
+	if err != nil {
+		transaction.Rollback()
+		return false, err
+	}
+
+	var translations []gormModels.Translation
+	if err := transaction.
+		Where("source_lexeme_id = ? OR target_lexeme_id = ?", lexeme.ID, lexeme.ID).
+		Find(&translations).Error; err != nil {
+		transaction.Rollback()
+		return false, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+
+	if len(translations) > 0 && !cascade {
+		transaction.Rollback()
+		return false, fmt.Errorf("%s word '%s' still has %d translations", lexeme.LanguageCode, lexeme.Word, len(translations))
+	}
+
+	var counterparts, translationIDs []uint
+	for _, translation := range translations {
+		translationIDs = append(translationIDs, translation.ID)
+		if translation.SourceLexemeID != lexeme.ID {
+			counterparts = append(counterparts, translation.SourceLexemeID)
+		}
+		if translation.TargetLexemeID != lexeme.ID {
+			counterparts = append(counterparts, translation.TargetLexemeID)
+		}
+		before, err := loadSnapshot(transaction, translation.ID)
+		if err != nil {
+			transaction.Rollback()
+			return false, err
+		}
+		if err := deleteTranslation(transaction, translation); err != nil {
+			transaction.Rollback()
+			return false, err
+		}
+		if err := recordRevision(transaction, revisionRemove, translation.ID, before); err != nil {
+			transaction.Rollback()
+			return false, err
+		}
+	}
+
+	if err := transaction.Delete(&lexeme).Error; err != nil {
+		transaction.Rollback()
+		return false, fmt.Errorf("failed to delete word: %w", err)
+	}
+
+	if err := removeOrphanedLexemes(transaction, counterparts...); err != nil {
+		transaction.Rollback()
+		return false, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	publish(db, events.TranslationRemoved, translationIDs...)
+
+	return true, nil
+}
+
+func lockLexeme(transaction *gorm.DB, id uint, language string) (gormModels.Lexeme, error) {
+	query := transaction.Clauses(clause.Locking{Strength: "UPDATE"})
+	if language != "" {
+		query = query.Where("language_code = ?", language)
+	}
+
+	var lexeme gormModels.Lexeme
+	if err := query.First(&lexeme, id).Error; err != nil {
+		return lexeme, fmt.Errorf("failed to fetch word: %w", err)
+	}
+
+	return lexeme, nil
+}
+
+func upsertLexeme(transaction *gorm.DB, language string, word string) (gormModels.Lexeme, error) {
+	var lexeme gormModels.Lexeme
+
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return lexeme, fmt.Errorf("word cannot be empty")
+	}
+	if err := checkLanguage(transaction, language); err != nil {
+		return lexeme, err
+	}
+
+	if err := transaction.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&gormModels.Lexeme{LanguageCode: language, Word: word}).Error; err != nil {
+		return lexeme, fmt.Errorf("failed to create %s word: %w", language, err)
+	}
+
+	if err := transaction.
+		Where("language_code = ? AND word = ?", language, word).
+		First(&lexeme).Error; err != nil {
+		return lexeme, fmt.Errorf("failed to fetch %s word: %w", language, err)
+	}
+
+	return lexeme, nil
+}
+
+// removeOrphanedLexemes deletes those of the given lexemes that are no longer
+// part of any translation.
+func removeOrphanedLexemes(transaction *gorm.DB, lexemeIDs ...uint) error {
+	for _, lexemeID := range lexemeIDs {
+		var translationCount int64
+		if err := transaction.Model(&gormModels.Translation{}).
+			Where("source_lexeme_id = ? OR target_lexeme_id = ?", lexemeID, lexemeID).
+			Count(&translationCount).Error; err != nil {
+			return fmt.Errorf("failed to count translations: %w", err)
+		}
+
+		if translationCount == 0 {
+			if err := transaction.
+				Delete(&gormModels.Lexeme{}, lexemeID).
+				Error; err != nil {
+				return fmt.Errorf("failed to delete word: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func lexemeCursor(lexeme gormModels.Lexeme) cursor {
+	return cursor{CreatedAt: lexeme.CreatedAt, ID: lexeme.ID}
+}
+
+func pageArgsFromInput(page *model.PageInput) pageArgs {
+	if page == nil {
+		return pageArgs{}
+	}
+
+	return pageArgs{First: page.First, After: page.After, Last: page.Last, Before: page.Before}
+}
+
+// convertLexeme leaves out the language and translations, which the GraphQL
+// layer loads for all lexemes of a response at once.
+func convertLexeme(lexeme gormModels.Lexeme) *model.Lexeme {
+	return &model.Lexeme{
+		ID:           strconv.Itoa(int(lexeme.ID)),
+		Word:         lexeme.Word,
+		CreatedAt:    lexeme.CreatedAt.String(),
+		UpdatedAt:    lexeme.UpdatedAt.String(),
+		LanguageCode: lexeme.LanguageCode,
+	}
+}
+
+// convertPolishWord converts lexeme to the deprecated PolishWord type.
+func convertPolishWord(lexeme gormModels.Lexeme) *model.PolishWord {
+	return &model.PolishWord{
+		ID:        strconv.Itoa(int(lexeme.ID)),
+		Word:      lexeme.Word,
+		CreatedAt: lexeme.CreatedAt.String(),
+		UpdatedAt: lexeme.UpdatedAt.String(),
+	}
+}
@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTranslationBetweenLanguages(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	input := model.TranslationInput{
+		Source:   &model.LexemeInput{Language: "de", Word: "schreiben"},
+		Target:   &model.LexemeInput{Language: "uk", Word: "писати"},
+		Examples: []*model.NewExampleInput{{Sentence: "Ich schreibe einen Brief."}},
+	}
+
+	translation, err := services.AddTranslation(testDB, ctx, input)
+	assert.NoError(t, err, "AddTranslation should not return an error")
+	expanded := expand(t, translation)
+	assert.Equal(t, "de", expanded.Source.Language.Code, "Source language should match")
+	assert.Equal(t, "schreiben", expanded.Source.Word, "Source word should match")
+	assert.Equal(t, "uk", expanded.Target.Language.Code, "Target language should match")
+	assert.Equal(t, "писати", expanded.Target.Word, "Target word should match")
+	assert.Equal(t, 1, len(expanded.Examples), "Examples should match")
+
+	_, err = services.AddTranslation(testDB, ctx, input)
+	assert.ErrorIs(t, err, services.ErrConflict, "Duplicate translation should conflict")
+
+	reverse, err := services.AddTranslation(testDB, ctx, model.TranslationInput{Source: input.Target, Target: input.Source})
+	assert.NoError(t, err, "Reverse translation should be a separate translation")
+	assert.Equal(t, translation.SourceLexemeID, reverse.TargetLexemeID, "Lexemes should be shared between directions")
+
+	_, err = services.AddTranslation(testDB, ctx, model.TranslationInput{
+		Source: &model.LexemeInput{Language: "xx", Word: "word"},
+		Target: input.Target,
+	})
+	assert.Error(t, err, "Unknown language should return an error")
+}
+
+func TestLexemes(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	services.AddTranslation(testDB, ctx, model.TranslationInput{
+		Source: &model.LexemeInput{Language: "de", Word: "schreiben"},
+		Target: &model.LexemeInput{Language: "en", Word: "write"},
+	})
+
+	write, err := services.LexemeByWord(testDB, ctx, "en", "write")
+	assert.NoError(t, err, "LexemeByWord should not return an error")
+	assert.Equal(t, "English", expandLexeme(t, write).Language.Name, "Language should be populated")
+
+	language := "en"
+	result, err := services.Lexemes(testDB, ctx, &model.LexemeFilter{Language: &language}, nil)
+	assert.NoError(t, err, "Lexemes should not return an error")
+	assert.Equal(t, 1, len(result.Edges), "English spellings should be shared by translations")
+
+	hasTranslations := true
+	result, err = services.Lexemes(testDB, ctx, &model.LexemeFilter{HasTranslations: &hasTranslations}, nil)
+	assert.NoError(t, err, "Lexemes should not return an error")
+	assert.Equal(t, 3, len(result.Edges), "Targets should count as translated")
+
+	schreiben, _ := services.LexemeByWord(testDB, ctx, "de", "schreiben")
+	translations := expandLexeme(t, schreiben).Translations
+	assert.Equal(t, 1, len(translations), "Outgoing translations should be populated")
+	assert.Equal(t, write.ID, expand(t, translations[0]).Target.ID, "Translation target should match")
+}
+
+func TestDeleteLexeme(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "type"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "spell"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "literować", EnglishWord: "spell"})
+
+	pisac, _ := services.LexemeByWord(testDB, ctx, "pl", "pisać")
+
+	_, err := services.DeleteLexeme(testDB, ctx, pisac.ID, false)
+	assert.Error(t, err, "Translated lexeme should not be deleted without cascade")
+
+	removed, err := services.DeleteLexeme(testDB, ctx, pisac.ID, true)
+	assert.NoError(t, err, "DeleteLexeme should not return an error")
+	assert.True(t, removed, "DeleteLexeme should return true")
+
+	_, err = services.LexemeByWord(testDB, ctx, "en", "write")
+	assert.Error(t, err, "Orphaned targets should be deleted")
+
+	_, err = services.LexemeByWord(testDB, ctx, "en", "spell")
+	assert.NoError(t, err, "Targets of other translations should be kept")
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TranslationsByEnglish returns the Polish translations of English words
+// spelled like word, grouped per English headword.
+func TranslationsByEnglish(db *gorm.DB, ctx context.Context, word string) ([]*model.TranslationGroup, error) {
+	return lookupTranslations(db.WithContext(ctx), englishLanguageCode, polishLanguageCode, word)
+}
+
+// TranslationsByPolish returns the English translations of Polish words
+// spelled like word, grouped per Polish headword.
+func TranslationsByPolish(db *gorm.DB, ctx context.Context, word string) ([]*model.TranslationGroup, error) {
+	return lookupTranslations(db.WithContext(ctx), polishLanguageCode, englishLanguageCode, word)
+}
+
+// lookupTranslations finds the lexemes of language matching word regardless of
+// case and diacritics, exact spellings first, and collects the translations
+// connecting each of them with a lexeme of counterpart in either direction.
+// Headwords without such translations are left out.
+func lookupTranslations(db *gorm.DB, language string, counterpart string, word string) ([]*model.TranslationGroup, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, fmt.Errorf("word cannot be empty")
+	}
+
+	var headwords []gormModels.Lexeme
+	if err := db.
+		Where("language_code = ? AND f_unaccent(lower(word)) = f_unaccent(lower(?))", language, word).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "word = ? DESC, word, id",
+			Vars:               []interface{}{word},
+			WithoutParentheses: true,
+		}}).
+		Find(&headwords).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch words: %w", err)
+	}
+
+	if len(headwords) == 0 {
+		return []*model.TranslationGroup{}, nil
+	}
+
+	ids := make([]uint, 0, len(headwords))
+	for _, headword := range headwords {
+		ids = append(ids, headword.ID)
+	}
+
+	var translations []gormModels.Translation
+	if err := db.
+		Joins("JOIN lexemes source_lexemes ON source_lexemes.id = translations.source_lexeme_id").
+		Joins("JOIN lexemes target_lexemes ON target_lexemes.id = translations.target_lexeme_id").
+		Where("translations.source_lexeme_id IN ? AND target_lexemes.language_code = ?", ids, counterpart).
+		Or("translations.target_lexeme_id IN ? AND source_lexemes.language_code = ?", ids, counterpart).
+		Order("translations.created_at, translations.id").
+		Find(&translations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+
+	byHeadword := make(map[uint][]*model.Translation, len(headwords))
+	for _, translation := range translations {
+		converted := convertTranslation(translation)
+		byHeadword[translation.SourceLexemeID] = append(byHeadword[translation.SourceLexemeID], converted)
+		if translation.TargetLexemeID != translation.SourceLexemeID {
+			byHeadword[translation.TargetLexemeID] = append(byHeadword[translation.TargetLexemeID], converted)
+		}
+	}
+
+	result := make([]*model.TranslationGroup, 0, len(headwords))
+	for _, headword := range headwords {
+		if len(byHeadword[headword.ID]) == 0 {
+			continue
+		}
+		result = append(result, &model.TranslationGroup{
+			Headword:     convertLexeme(headword),
+			Translations: byHeadword[headword.ID],
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslationsByEnglish(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zapisać", EnglishWord: "write"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "type"})
+	services.AddTranslation(testDB, ctx, model.TranslationInput{
+		Source: &model.LexemeInput{Language: "de", Word: "schreiben"},
+		Target: &model.LexemeInput{Language: "en", Word: "write"},
+	})
+
+	groups, err := services.TranslationsByEnglish(testDB, ctx, "Write")
+	assert.NoError(t, err, "TranslationsByEnglish should not return an error")
+	assert.Equal(t, 1, len(groups), "Identical spellings should share a headword")
+	assert.Equal(t, "write", groups[0].Headword.Word, "Headword should match")
+	assert.Equal(t, 2, len(groups[0].Translations), "Only Polish translations should be listed")
+	assert.Equal(t, "pisać", expand(t, groups[0].Translations[0]).Source.Word, "Polish word should match")
+	assert.Equal(t, "zapisać", expand(t, groups[0].Translations[1]).Source.Word, "Polish word should match")
+
+	groups, err = services.TranslationsByEnglish(testDB, ctx, "read")
+	assert.NoError(t, err, "TranslationsByEnglish should not return an error")
+	assert.Empty(t, groups, "Unknown words should have no groups")
+}
+
+func TestTranslationsByPolish(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "type"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisac", EnglishWord: "scribble"})
+
+	groups, err := services.TranslationsByPolish(testDB, ctx, "pisać")
+	assert.NoError(t, err, "TranslationsByPolish should not return an error")
+	assert.Equal(t, 2, len(groups), "Spellings differing in diacritics should be separate headwords")
+	assert.Equal(t, "pisać", groups[0].Headword.Word, "Exact spelling should come first")
+	assert.Equal(t, 2, len(groups[0].Translations), "Translations should be grouped per headword")
+	assert.Equal(t, "pisac", groups[1].Headword.Word, "Headword should match")
+	assert.Equal(t, "scribble", expand(t, groups[1].Translations[0]).Target.Word, "English word should match")
+
+	_, err = services.TranslationsByPolish(testDB, ctx, " ")
+	assert.Error(t, err, "Empty word should return an error")
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+)
+
+// NewMemoryTranslationRepository returns an empty TranslationRepository kept
+// in memory, which knows the default languages. It is safe for concurrent
+// use. Removed entries are gone for good, and no history or events are kept.
+func NewMemoryTranslationRepository() TranslationRepository {
+	languages := make(map[string]bool, len(gormModels.DefaultLanguages))
+	for _, language := range gormModels.DefaultLanguages {
+		languages[language.Code] = true
+	}
+
+	return &memoryTranslationRepository{
+		languages:    languages,
+		lexemes:      map[uint]gormModels.Lexeme{},
+		words:        map[lexemeKey]uint{},
+		translations: map[uint]gormModels.Translation{},
+		examples:     map[uint][]gormModels.Example{},
+	}
+}
+
+type lexemeKey struct {
+	language string
+	word     string
+}
+
+type memoryTranslationRepository struct {
+	mu        sync.RWMutex
+	languages map[string]bool
+	lexemes   map[uint]gormModels.Lexeme
+	// words indexes the lexemes by language and spelling.
+	words        map[lexemeKey]uint
+	translations map[uint]gormModels.Translation
+	// examples holds the examples of each translation in order.
+	examples map[uint][]gormModels.Example
+
+	lastLexemeID      uint
+	lastTranslationID uint
+	lastExampleID     uint
+}
+
+func (r *memoryTranslationRepository) Add(ctx context.Context, input model.TranslationInput) (*model.Translation, error) {
+	if input.Source == nil || input.Target == nil {
+		return nil, fmt.Errorf("source and target are required")
+	}
+
+	sentences, err := checkSentences(input.Examples)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, err := r.checkLexeme(input.Source.Language, input.Source.Word)
+	if err != nil {
+		return nil, err
+	}
+	target, err := r.checkLexeme(input.Target.Language, input.Target.Word)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkConflict(0, source, target); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	source, target = r.saveLexeme(source, now), r.saveLexeme(target, now)
+
+	r.lastTranslationID++
+	translation := gormModels.Translation{
+		ID:             r.lastTranslationID,
+		SourceLexemeID: source.ID,
+		TargetLexemeID: target.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	r.translations[translation.ID] = translation
+	r.examples[translation.ID] = r.appendExamples(translation.ID, nil, sentences, now)
+
+	return convertTranslation(translation), nil
+}
+
+func (r *memoryTranslationRepository) Update(ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error) {
+	intID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+	sourceInput, targetInput := updatedLexemes(input)
+	sentences, err := checkSentences(input.AddExamples)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	translation, ok := r.translations[uint(intID)]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	source := r.lexemes[translation.SourceLexemeID]
+	if sourceInput != nil {
+		if source, err = r.checkLexeme(sourceInput.Language, sourceInput.Word); err != nil {
+			return nil, err
+		}
+	}
+	target := r.lexemes[translation.TargetLexemeID]
+	if targetInput != nil {
+		if target, err = r.checkLexeme(targetInput.Language, targetInput.Word); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.checkConflict(translation.ID, source, target); err != nil {
+		return nil, err
+	}
+
+	kept, err := r.keptExamples(translation.ID, input.RemoveExampleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	source, target = r.saveLexeme(source, now), r.saveLexeme(target, now)
+
+	previousSourceID := translation.SourceLexemeID
+	previousTargetID := translation.TargetLexemeID
+	translation.SourceLexemeID = source.ID
+	translation.TargetLexemeID = target.ID
+	translation.UpdatedAt = now
+	r.translations[translation.ID] = translation
+	r.examples[translation.ID] = r.appendExamples(translation.ID, kept, sentences, now)
+	r.removeOrphanedLexemes(previousSourceID, previousTargetID)
+
+	return convertTranslation(translation), nil
+}
+
+func (r *memoryTranslationRepository) Remove(ctx context.Context, id string) (bool, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid id format: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	translation, ok := r.translations[uint(intID)]
+	if !ok {
+		return false, fmt.Errorf("failed to fetch translation: %w", gorm.ErrRecordNotFound)
+	}
+
+	delete(r.translations, translation.ID)
+	delete(r.examples, translation.ID)
+	r.removeOrphanedLexemes(translation.SourceLexemeID, translation.TargetLexemeID)
+
+	return true, nil
+}
+
+func (r *memoryTranslationRepository) Translation(ctx context.Context, id string) (*model.Translation, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	translation, ok := r.translations[uint(intID)]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return convertTranslation(translation), nil
+}
+
+func (r *memoryTranslationRepository) Translations(ctx context.Context) ([]*model.Translation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]uint, 0, len(r.translations))
+	for id := range r.translations {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var result []*model.Translation
+	for _, id := range ids {
+		result = append(result, convertTranslation(r.translations[id]))
+	}
+
+	return result, nil
+}
+
+func (r *memoryTranslationRepository) LexemeByWord(ctx context.Context, language string, word string) (*model.Lexeme, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.words[lexemeKey{language: language, word: strings.TrimSpace(word)}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return convertLexeme(r.lexemes[id]), nil
+}
+
+func (r *memoryTranslationRepository) LexemesByID(ctx context.Context, ids []uint) (map[uint]*model.Lexeme, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uint]*model.Lexeme, len(ids))
+	for _, id := range ids {
+		if lexeme, ok := r.lexemes[id]; ok {
+			result[id] = convertLexeme(lexeme)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *memoryTranslationRepository) ExamplesByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.Example, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uint][]*model.Example, len(translationIDs))
+	for _, id := range translationIDs {
+		for _, example := range r.examples[id] {
+			result[id] = append(result[id], convertExample(example))
+		}
+	}
+
+	return result, nil
+}
+
+// checkLexeme validates a lexeme like upsertLexeme does and returns it. A
+// lexeme that does not exist yet is returned without an id and is only
+// stored by saveLexeme, once nothing else can fail.
+func (r *memoryTranslationRepository) checkLexeme(language string, word string) (gormModels.Lexeme, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return gormModels.Lexeme{}, fmt.Errorf("word cannot be empty")
+	}
+	if !r.languages[language] {
+		return gormModels.Lexeme{}, fmt.Errorf("unsupported language '%s'", language)
+	}
+
+	if id, ok := r.words[lexemeKey{language: language, word: word}]; ok {
+		return r.lexemes[id], nil
+	}
+
+	return gormModels.Lexeme{LanguageCode: language, Word: word}, nil
+}
+
+// saveLexeme stores a lexeme returned by checkLexeme unless it exists.
+func (r *memoryTranslationRepository) saveLexeme(lexeme gormModels.Lexeme, now time.Time) gormModels.Lexeme {
+	key := lexemeKey{language: lexeme.LanguageCode, word: lexeme.Word}
+	if id, ok := r.words[key]; ok {
+		return r.lexemes[id]
+	}
+
+	r.lastLexemeID++
+	lexeme.ID = r.lastLexemeID
+	lexeme.CreatedAt = now
+	lexeme.UpdatedAt = now
+	r.lexemes[lexeme.ID] = lexeme
+	r.words[key] = lexeme.ID
+
+	return lexeme
+}
+
+// checkConflict is checkTranslationConflict for the memory repository.
+func (r *memoryTranslationRepository) checkConflict(translationID uint, source gormModels.Lexeme, target gormModels.Lexeme) error {
+	if source.ID == 0 || target.ID == 0 {
+		return nil
+	}
+
+	for _, translation := range r.translations {
+		if translation.ID != translationID &&
+			translation.SourceLexemeID == source.ID &&
+			translation.TargetLexemeID == target.ID {
+			return translationConflict(source, target)
+		}
+	}
+
+	return nil
+}
+
+// keptExamples returns the examples of a translation left after removing
+// those with removeIDs, all of which have to belong to it.
+func (r *memoryTranslationRepository) keptExamples(translationID uint, removeIDs []string) ([]gormModels.Example, error) {
+	toRemove := make(map[string]bool, len(removeIDs))
+	for _, id := range removeIDs {
+		toRemove[id] = true
+	}
+
+	examples := r.examples[translationID]
+	kept := make([]gormModels.Example, 0, len(examples))
+	for _, example := range examples {
+		id := strconv.Itoa(int(example.ID))
+		if toRemove[id] {
+			delete(toRemove, id)
+			continue
+		}
+		kept = append(kept, example)
+	}
+	for id := range toRemove {
+		return nil, fmt.Errorf("example %s does not belong to translation %d", id, translationID)
+	}
+
+	return kept, nil
+}
+
+// appendExamples adds examples with the checked sentences after kept and
+// numbers all of them by their place in the list.
+func (r *memoryTranslationRepository) appendExamples(translationID uint, kept []gormModels.Example, sentences []string, now time.Time) []gormModels.Example {
+	for _, sentence := range sentences {
+		r.lastExampleID++
+		kept = append(kept, gormModels.Example{
+			ID:            r.lastExampleID,
+			Sentence:      sentence,
+			TranslationID: translationID,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+	for position := range kept {
+		kept[position].Position = position
+	}
+
+	return kept
+}
+
+// removeOrphanedLexemes is removeOrphanedLexemes for the memory repository.
+func (r *memoryTranslationRepository) removeOrphanedLexemes(lexemeIDs ...uint) {
+	for _, lexemeID := range lexemeIDs {
+		used := false
+		for _, translation := range r.translations {
+			if translation.SourceLexemeID == lexemeID || translation.TargetLexemeID == lexemeID {
+				used = true
+				break
+			}
+		}
+
+		if lexeme, ok := r.lexemes[lexemeID]; ok && !used {
+			delete(r.lexemes, lexemeID)
+			delete(r.words, lexemeKey{language: lexeme.LanguageCode, word: lexeme.Word})
+		}
+	}
+}
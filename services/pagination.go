@@ -0,0 +1,177 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// cursor identifies a row by its position in (created_at, id) order, which
+// stays stable while new rows are inserted.
+type cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+func encodeCursor(c cursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(value string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor{}, fmt.Errorf("invalid cursor %q", value)
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return cursor{}, fmt.Errorf("invalid cursor %q", value)
+	}
+
+	parsedCreatedAt, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return cursor{}, fmt.Errorf("invalid cursor %q", value)
+	}
+	parsedID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return cursor{}, fmt.Errorf("invalid cursor %q", value)
+	}
+
+	return cursor{CreatedAt: parsedCreatedAt, ID: uint(parsedID)}, nil
+}
+
+type pageArgs struct {
+	First  *int32
+	After  *string
+	Last   *int32
+	Before *string
+}
+
+func (args pageArgs) limit() (int, error) {
+	if args.First != nil && args.Last != nil {
+		return 0, fmt.Errorf("first and last cannot be used together")
+	}
+
+	limit := defaultPageSize
+	name := "first"
+	if args.First != nil {
+		limit = int(*args.First)
+	}
+	if args.Last != nil {
+		limit = int(*args.Last)
+		name = "last"
+	}
+	if limit < 0 || limit > maxPageSize {
+		return 0, fmt.Errorf("%s must be between 0 and %d", name, maxPageSize)
+	}
+
+	return limit, nil
+}
+
+// paginate runs a keyset-paginated query over (created_at, id) of table.
+// base holds the filtering conditions.
+func paginate[T any](
+	base *gorm.DB,
+	table string,
+	args pageArgs,
+	key func(T) cursor,
+) ([]T, *model.PageInfo, error) {
+	limit, err := args.limit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var after, before *cursor
+	if args.After != nil {
+		c, err := decodeCursor(*args.After)
+		if err != nil {
+			return nil, nil, err
+		}
+		after = &c
+	}
+	if args.Before != nil {
+		c, err := decodeCursor(*args.Before)
+		if err != nil {
+			return nil, nil, err
+		}
+		before = &c
+	}
+
+	keyColumns := fmt.Sprintf("(%s.created_at, %s.id)", table, table)
+	backward := args.Last != nil
+
+	query := base.Session(&gorm.Session{})
+	if after != nil {
+		query = query.Where(keyColumns+" > (?, ?)", after.CreatedAt, after.ID)
+	}
+	if before != nil {
+		query = query.Where(keyColumns+" < (?, ?)", before.CreatedAt, before.ID)
+	}
+	order := fmt.Sprintf("%s.created_at ASC, %s.id ASC", table, table)
+	if backward {
+		order = fmt.Sprintf("%s.created_at DESC, %s.id DESC", table, table)
+	}
+
+	var rows []T
+	if err := query.Order(order).Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	pageInfo := &model.PageInfo{}
+	if backward {
+		pageInfo.HasPreviousPage = hasMore
+		if before != nil {
+			pageInfo.HasNextPage, err = rowExists(base, table, keyColumns+" >= (?, ?)", before)
+		}
+	} else {
+		pageInfo.HasNextPage = hasMore
+		if after != nil {
+			pageInfo.HasPreviousPage, err = rowExists(base, table, keyColumns+" <= (?, ?)", after)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(rows) > 0 {
+		startCursor := encodeCursor(key(rows[0]))
+		endCursor := encodeCursor(key(rows[len(rows)-1]))
+		pageInfo.StartCursor = &startCursor
+		pageInfo.EndCursor = &endCursor
+	}
+
+	return rows, pageInfo, nil
+}
+
+func rowExists(base *gorm.DB, table string, condition string, c *cursor) (bool, error) {
+	var found []uint
+	if err := base.Session(&gorm.Session{}).
+		Where(condition, c.CreatedAt, c.ID).
+		Limit(1).
+		Pluck(table+".id", &found).Error; err != nil {
+		return false, err
+	}
+
+	return len(found) > 0, nil
+}
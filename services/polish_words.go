@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+)
+
+// The functions below serve the deprecated PolishWord API, which exposes
+// lexemes in Polish.
+
+func PolishWord(db *gorm.DB, ctx context.Context, id string) (*model.PolishWord, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	return findPolishWord(db.WithContext(ctx), "id = ?", intID)
+}
+
+func PolishWordByText(db *gorm.DB, ctx context.Context, word string) (*model.PolishWord, error) {
+	return findPolishWord(db.WithContext(ctx), "word = ?", strings.TrimSpace(word))
+}
+
+func PolishWords(db *gorm.DB, ctx context.Context, filter *model.PolishWordFilter, page *model.PageInput) (*model.PolishWordConnection, error) {
+	language := polishLanguageCode
+	lexemeFilter := &model.LexemeFilter{Language: &language}
+	if filter != nil {
+		lexemeFilter.Prefix = filter.Prefix
+		lexemeFilter.Contains = filter.Contains
+		lexemeFilter.HasTranslations = filter.HasTranslations
+	}
+
+	lexemes, pageInfo, err := findLexemes(db.WithContext(ctx), lexemeFilter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*model.PolishWordEdge, 0, len(lexemes))
+	for _, lexeme := range lexemes {
+		edges = append(edges, &model.PolishWordEdge{
+			Cursor: encodeCursor(lexemeCursor(lexeme)),
+			Node:   convertPolishWord(lexeme),
+		})
+	}
+
+	return &model.PolishWordConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func RenamePolishWord(db *gorm.DB, ctx context.Context, id string, word string) (*model.PolishWord, error) {
+	lexeme, err := renameLexeme(db.WithContext(ctx), id, polishLanguageCode, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return findPolishWord(db.WithContext(ctx), "id = ?", lexeme.ID)
+}
+
+// DeletePolishWord removes a Polish word. Words that still have translations
+// are only removed when cascade is set, in which case the translations and
+// their examples go with them.
+func DeletePolishWord(db *gorm.DB, ctx context.Context, id string, cascade bool) (bool, error) {
+	return deleteLexeme(db.WithContext(ctx), id, polishLanguageCode, cascade)
+}
+
+func findPolishWord(db *gorm.DB, query string, args ...interface{}) (*model.PolishWord, error) {
+	var lexeme gormModels.Lexeme
+	if err := db.
+		Where("language_code = ?", polishLanguageCode).
+		Where(query, args...).
+		First(&lexeme).Error; err != nil {
+		return nil, err
+	}
+
+	return convertPolishWord(lexeme), nil
+}
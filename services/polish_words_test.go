@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolishWordTranslations(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	created, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "type"})
+
+	polishWord, err := services.PolishWord(testDB, ctx, expand(t, created).PolishWord.ID)
+	assert.NoError(t, err, "PolishWord should not return an error")
+	assert.Equal(t, "pisać", polishWord.Word, "Word should match")
+	assert.Equal(t, 2, len(translationsOf(t, polishWord.ID)), "Translations should be populated")
+
+	byText, err := services.PolishWordByText(testDB, ctx, "pisać")
+	assert.NoError(t, err, "PolishWordByText should not return an error")
+	assert.Equal(t, polishWord.ID, byText.ID, "ID should match")
+
+	translation, err := services.Translation(testDB, ctx, created.ID)
+	assert.NoError(t, err, "Translation should not return an error")
+	assert.Equal(t, 2, len(expand(t, translation).PolishWord.Translations), "Nested translations should be populated")
+
+	reversed, err := services.AddTranslation(testDB, ctx, model.TranslationInput{
+		Source: &model.LexemeInput{Language: "en", Word: "pen"},
+		Target: &model.LexemeInput{Language: "pl", Word: "pisać"},
+	})
+	assert.NoError(t, err, "AddTranslation should not return an error")
+	assert.Equal(t, 3, len(translationsOf(t, polishWord.ID)), "Translations into the word should be populated")
+	assert.Equal(t, "pisać", expand(t, reversed).PolishWord.Word, "PolishWord should be the Polish target")
+	assert.Equal(t, "pen", expand(t, reversed).EnglishWord, "EnglishWord should be the English source")
+}
+
+func TestPolishWords(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	for _, word := range []string{"pisać", "przepisać", "pić", "jeść"} {
+		services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: word, EnglishWord: "word"})
+	}
+
+	prefix := "pi"
+	result, err := services.PolishWords(testDB, ctx, &model.PolishWordFilter{Prefix: &prefix}, nil)
+	assert.NoError(t, err, "PolishWords should not return an error")
+	assert.Equal(t, 2, len(result.Edges), "Only words starting with the prefix should match")
+
+	contains := "pisac"
+	result, err = services.PolishWords(testDB, ctx, &model.PolishWordFilter{Contains: &contains}, nil)
+	assert.NoError(t, err, "PolishWords should not return an error")
+	assert.Equal(t, 2, len(result.Edges), "Contains should ignore diacritics")
+
+	first := int32(3)
+	result, err = services.PolishWords(testDB, ctx, nil, &model.PageInput{First: &first})
+	assert.NoError(t, err, "PolishWords should not return an error")
+	assert.Equal(t, 3, len(result.Edges), "Page length should match")
+	assert.True(t, result.PageInfo.HasNextPage, "There should be a next page")
+
+	result, err = services.PolishWords(testDB, ctx, nil, &model.PageInput{First: &first, After: result.PageInfo.EndCursor})
+	assert.NoError(t, err, "PolishWords should not return an error")
+	assert.Equal(t, 1, len(result.Edges), "Second page length should match")
+	assert.Equal(t, "jeść", result.Edges[0].Node.Word, "Word should match")
+}
+
+func TestRenamePolishWord(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	created, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisac", EnglishWord: "write"})
+	services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pić", EnglishWord: "drink"})
+
+	polishWordID := expand(t, created).PolishWord.ID
+	renamed, err := services.RenamePolishWord(testDB, ctx, polishWordID, "pisać")
+	assert.NoError(t, err, "RenamePolishWord should not return an error")
+	assert.Equal(t, "pisać", renamed.Word, "Word should be renamed")
+	assert.Equal(t, 1, len(translationsOf(t, renamed.ID)), "Translations should be kept")
+
+	_, err = services.RenamePolishWord(testDB, ctx, polishWordID, "pić")
+	assert.Error(t, err, "Renaming to an existing word should return an error")
+	assert.Contains(t, err.Error(), "already exists", fmt.Sprintf("expected already exists, got: %v", err))
+}
+
+func TestDeletePolishWord(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	created, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "pisać",
+		EnglishWord: "write",
+		Examples: []*model.NewExampleInput{
+			{Sentence: "On lubi pisać listy."},
+		},
+	})
+
+	polishWordID := expand(t, created).PolishWord.ID
+	deleted, err := services.DeletePolishWord(testDB, ctx, polishWordID, false)
+	assert.Error(t, err, "Deleting a word with translations should fail without cascade")
+	assert.False(t, deleted, "deleted should be false")
+
+	deleted, err = services.DeletePolishWord(testDB, ctx, polishWordID, true)
+	assert.NoError(t, err, "DeletePolishWord should not return an error")
+	assert.True(t, deleted, "deleted should be true")
+
+	_, err = services.Translation(testDB, ctx, created.ID)
+	assert.Error(t, err, "Quering translation of deleted word should return an error")
+	_, err = services.PolishWord(testDB, ctx, polishWordID)
+	assert.Error(t, err, "Quering deleted word should return an error")
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"gorm.io/gorm"
+)
+
+// TranslationRepository adds, updates, removes and looks up translations
+// together with their lexemes and examples. It covers translation CRUD only:
+// search, the trash, word lists, reviews, revisions, import and export and
+// users are not part of it and work on a *gorm.DB directly. Every
+// implementation follows the same rules:
+//
+//   - a lexeme is created on first use and there is one per language and
+//     spelling, ignoring surrounding whitespace;
+//   - two lexemes are connected by at most one translation, any other attempt
+//     fails with ErrConflict;
+//   - removing a translation removes its examples, and so does moving it to
+//     another lexeme remove the previous lexeme, once nothing refers to it;
+//   - looking up something that does not exist fails with
+//     gorm.ErrRecordNotFound;
+//   - a change that fails leaves everything as it was.
+//
+// What LexemesByID and ExamplesByTranslation return for removed entries is up
+// to the implementation.
+type TranslationRepository interface {
+	Add(ctx context.Context, input model.TranslationInput) (*model.Translation, error)
+	Update(ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error)
+	Remove(ctx context.Context, id string) (bool, error)
+	Translation(ctx context.Context, id string) (*model.Translation, error)
+	Translations(ctx context.Context) ([]*model.Translation, error)
+	LexemeByWord(ctx context.Context, language string, word string) (*model.Lexeme, error)
+	LexemesByID(ctx context.Context, ids []uint) (map[uint]*model.Lexeme, error)
+	ExamplesByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.Example, error)
+}
+
+// NewGormTranslationRepository returns the TranslationRepository stored in db.
+// Besides the rules every repository follows, it keeps removed translations
+// in the trash, records their history and publishes their events, and runs
+// changes again when they fail with a serialization failure or a deadlock.
+func NewGormTranslationRepository(db *gorm.DB) TranslationRepository {
+	return gormTranslationRepository{db: db}
+}
+
+type gormTranslationRepository struct {
+	db *gorm.DB
+}
+
+func (r gormTranslationRepository) Add(ctx context.Context, input model.TranslationInput) (*model.Translation, error) {
+	return retryTransaction(ctx, func() (*model.Translation, error) {
+		return AddTranslation(r.db, ctx, input)
+	})
+}
+
+func (r gormTranslationRepository) Update(ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error) {
+	return retryTransaction(ctx, func() (*model.Translation, error) {
+		return UpdateTranslation(r.db, ctx, input)
+	})
+}
+
+func (r gormTranslationRepository) Remove(ctx context.Context, id string) (bool, error) {
+	return retryTransaction(ctx, func() (bool, error) {
+		return RemoveTranslation(r.db, ctx, id)
+	})
+}
+
+func (r gormTranslationRepository) Translation(ctx context.Context, id string) (*model.Translation, error) {
+	return Translation(r.db, ctx, id)
+}
+
+func (r gormTranslationRepository) Translations(ctx context.Context) ([]*model.Translation, error) {
+	return Translations(r.db, ctx)
+}
+
+func (r gormTranslationRepository) LexemeByWord(ctx context.Context, language string, word string) (*model.Lexeme, error) {
+	return LexemeByWord(r.db, ctx, language, word)
+}
+
+func (r gormTranslationRepository) LexemesByID(ctx context.Context, ids []uint) (map[uint]*model.Lexeme, error) {
+	return LexemesByID(r.db, ctx, ids)
+}
+
+func (r gormTranslationRepository) ExamplesByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.Example, error) {
+	return ExamplesByTranslation(r.db, ctx, translationIDs)
+}
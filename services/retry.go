@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/retry"
+)
+
+// transactionRetry is how often and how long apart the transactions of the
+// Service are attempted while they fail with serialization failures.
+var transactionRetry = retry.Policy{
+	Attempts: 5,
+	Initial:  20 * time.Millisecond,
+	Max:      500 * time.Millisecond,
+}
+
+// retryTransaction runs fn, a function running a transaction, again for as
+// long as the transaction is rolled back because of a concurrent one. fn must
+// have no effects outside of the transaction before it commits.
+func retryTransaction[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var result T
+	err := retry.Do(ctx, transactionRetry, isSerializationFailure, nil, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+
+	return result, err
+}
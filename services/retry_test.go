@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransaction(t *testing.T) {
+	for _, code := range []string{serializationFailureCode, deadlockDetectedCode} {
+		calls := 0
+		result, err := retryTransaction(context.Background(), func() (int, error) {
+			calls++
+			if calls < 3 {
+				return 0, fmt.Errorf("failed to commit transaction: %w", &pgconn.PgError{Code: code})
+			}
+			return calls, nil
+		})
+		assert.NoError(t, err, fmt.Sprintf("Transaction failing with %s should be retried", code))
+		assert.Equal(t, 3, result, "Result of the successful attempt should be returned")
+	}
+
+	calls := 0
+	_, err := retryTransaction(context.Background(), func() (int, error) {
+		calls++
+		return 0, &pgconn.PgError{Code: serializationFailureCode}
+	})
+	assert.Error(t, err, "Transaction failing every attempt should return an error")
+	assert.Equal(t, transactionRetry.Attempts, calls, "Transaction should be attempted a limited number of times")
+
+	calls = 0
+	_, err = retryTransaction(context.Background(), func() (int, error) {
+		calls++
+		return 0, &pgconn.PgError{Code: uniqueViolationCode}
+	})
+	assert.Error(t, err, "Other errors should be returned")
+	assert.Equal(t, 1, calls, "Other errors should not be retried")
+}
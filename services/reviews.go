@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"github.com/pgrzankowski/dictionary-app/srs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxDueReviews = 100
+
+// DueReviews returns up to limit translations the user should review now,
+// most overdue first, followed by the ones the user has never reviewed.
+func DueReviews(db *gorm.DB, ctx context.Context, clock srs.Clock, userID uint, limit *int32) ([]*model.Review, error) {
+	maxResults := 20
+	if limit != nil {
+		maxResults = int(*limit)
+	}
+	if maxResults < 1 || maxResults > maxDueReviews {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxDueReviews)
+	}
+
+	now := clock.Now()
+
+	var due []struct {
+		TranslationID uint
+	}
+	if err := db.WithContext(ctx).
+		Table("translations t").
+		Select("t.id AS translation_id").
+		Joins("LEFT JOIN reviews r ON r.translation_id = t.id AND r.user_id = ?", userID).
+		Where("t.deleted_at IS NULL").
+		Where("r.id IS NULL OR r.due_at <= ?", now).
+		Order("r.due_at NULLS LAST, t.id").
+		Limit(maxResults).
+		Scan(&due).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch due reviews: %w", err)
+	}
+
+	if len(due) == 0 {
+		return []*model.Review{}, nil
+	}
+
+	ids := make([]uint, 0, len(due))
+	for _, row := range due {
+		ids = append(ids, row.TranslationID)
+	}
+
+	var reviews []gormModels.Review
+	if err := db.WithContext(ctx).
+		Where("user_id = ? AND translation_id IN ?", userID, ids).
+		Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+
+	var translations []gormModels.Translation
+	if err := db.WithContext(ctx).Find(&translations, ids).Error; err != nil {
+		return nil, err
+	}
+
+	reviewByTranslation := make(map[uint]gormModels.Review, len(reviews))
+	for _, review := range reviews {
+		reviewByTranslation[review.TranslationID] = review
+	}
+	translationByID := make(map[uint]gormModels.Translation, len(translations))
+	for _, translation := range translations {
+		translationByID[translation.ID] = translation
+	}
+
+	result := make([]*model.Review, 0, len(ids))
+	for _, id := range ids {
+		translation, ok := translationByID[id]
+		if !ok {
+			continue
+		}
+		review, ok := reviewByTranslation[id]
+		if !ok {
+			review = newReview(userID, id, now)
+		}
+		result = append(result, convertReview(review, convertTranslation(translation)))
+	}
+
+	return result, nil
+}
+
+// SubmitReview grades the user's recall of a translation and schedules its
+// next review with SM-2.
+func SubmitReview(db *gorm.DB, ctx context.Context, clock srs.Clock, userID uint, translationID string, grade int32) (*model.Review, error) {
+	intID, err := strconv.Atoi(translationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	now := clock.Now()
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	var translation gormModels.Translation
+	if err := transaction.First(&translation, intID).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch translation: %w", err)
+	}
+
+	var review gormModels.Review
+	err = transaction.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND translation_id = ?", userID, translation.ID).
+		First(&review).Error
+	if err == gorm.ErrRecordNotFound {
+		review = newReview(userID, translation.ID, now)
+	} else if err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch review: %w", err)
+	}
+
+	next, err := srs.NewScheduler(clock).Review(srs.State{
+		EaseFactor:  review.EaseFactor,
+		Interval:    review.Interval,
+		Repetitions: review.Repetitions,
+		Due:         review.DueAt,
+	}, int(grade))
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	review.EaseFactor = next.EaseFactor
+	review.Interval = next.Interval
+	review.Repetitions = next.Repetitions
+	review.DueAt = next.Due
+	review.LastReviewedAt = now
+	if err := transaction.Omit(clause.Associations).Save(&review).Error; err != nil {
+		transaction.Rollback()
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: translation %d is already being reviewed", ErrConflict, translation.ID)
+		}
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	converted, err := findTranslation(db.WithContext(ctx), translation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertReview(review, converted), nil
+}
+
+// newReview returns the unsaved review of a translation the user has never
+// reviewed.
+func newReview(userID uint, translationID uint, now time.Time) gormModels.Review {
+	state := srs.NewState(now)
+	return gormModels.Review{
+		UserID:        userID,
+		TranslationID: translationID,
+		EaseFactor:    state.EaseFactor,
+		Interval:      state.Interval,
+		Repetitions:   state.Repetitions,
+		DueAt:         state.Due,
+	}
+}
+
+func convertReview(review gormModels.Review, translation *model.Translation) *model.Review {
+	result := &model.Review{
+		Translation: translation,
+		EaseFactor:  review.EaseFactor,
+		Interval:    int32(review.Interval),
+		Repetitions: int32(review.Repetitions),
+		DueAt:       review.DueAt.String(),
+	}
+	if !review.LastReviewedAt.IsZero() {
+		lastReviewedAt := review.LastReviewedAt.String()
+		result.LastReviewedAt = &lastReviewedAt
+	}
+
+	return result
+}
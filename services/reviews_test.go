@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestReviews(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	clock := &testClock{now: time.Date(2025, 2, 20, 12, 0, 0, 0, time.UTC)}
+	alice, _ := services.Register(testDB, ctx, "alice@example.com", "password")
+	bob, _ := services.Register(testDB, ctx, "bob@example.com", "password")
+	aliceID, _ := strconv.Atoi(alice.ID)
+	bobID, _ := strconv.Atoi(bob.ID)
+	write, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	drink, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pić", EnglishWord: "drink"})
+
+	due, err := services.DueReviews(testDB, ctx, clock, uint(aliceID), nil)
+	assert.NoError(t, err, "DueReviews should not return an error")
+	assert.Equal(t, 2, len(due), "New translations should be due")
+	assert.Nil(t, due[0].LastReviewedAt, "New translations should not have been reviewed")
+
+	review, err := services.SubmitReview(testDB, ctx, clock, uint(aliceID), write.ID, 4)
+	assert.NoError(t, err, "SubmitReview should not return an error")
+	assert.Equal(t, int32(1), review.Interval, "First interval should be one day")
+	assert.Equal(t, write.ID, review.Translation.ID, "Translation should match")
+
+	due, _ = services.DueReviews(testDB, ctx, clock, uint(aliceID), nil)
+	assert.Equal(t, 1, len(due), "Reviewed translation should not be due")
+	assert.Equal(t, drink.ID, due[0].Translation.ID, "Unreviewed translation should be due")
+
+	due, _ = services.DueReviews(testDB, ctx, clock, uint(bobID), nil)
+	assert.Equal(t, 2, len(due), "Reviews should be kept per user")
+
+	clock.now = clock.now.AddDate(0, 0, 2)
+	due, _ = services.DueReviews(testDB, ctx, clock, uint(aliceID), nil)
+	assert.Equal(t, 2, len(due), "Reviewed translation should be due again")
+	assert.Equal(t, write.ID, due[0].Translation.ID, "Overdue reviews should come first")
+
+	review, err = services.SubmitReview(testDB, ctx, clock, uint(aliceID), write.ID, 4)
+	assert.NoError(t, err, "SubmitReview should not return an error")
+	assert.Equal(t, int32(6), review.Interval, "Second interval should be six days")
+	assert.Equal(t, int32(2), review.Repetitions, "Repetitions should be counted")
+
+	_, err = services.SubmitReview(testDB, ctx, clock, uint(aliceID), write.ID, 7)
+	assert.Error(t, err, "Invalid grade should return an error")
+}
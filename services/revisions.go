@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type lexemeSnapshot struct {
+	Language string `json:"language"`
+	Word     string `json:"word"`
+}
+
+// translationSnapshot is the self-contained form of a translation used by
+// exports and revisions.
+type translationSnapshot struct {
+	ID        uint           `json:"id"`
+	Source    lexemeSnapshot `json:"source"`
+	Target    lexemeSnapshot `json:"target"`
+	Examples  []string       `json:"examples"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// preloadSnapshot loads what a snapshot contains.
+func preloadSnapshot(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("SourceLexeme").
+		Preload("TargetLexeme").
+		Preload("Examples", orderExamples)
+}
+
+func snapshotTranslation(translation gormModels.Translation) translationSnapshot {
+	examples := make([]string, 0, len(translation.Examples))
+	for _, example := range translation.Examples {
+		examples = append(examples, example.Sentence)
+	}
+
+	return translationSnapshot{
+		ID:        translation.ID,
+		Source:    lexemeSnapshot{Language: translation.SourceLexeme.LanguageCode, Word: translation.SourceLexeme.Word},
+		Target:    lexemeSnapshot{Language: translation.TargetLexeme.LanguageCode, Word: translation.TargetLexeme.Word},
+		Examples:  examples,
+		CreatedAt: translation.CreatedAt,
+		UpdatedAt: translation.UpdatedAt,
+	}
+}
+
+// The actions recorded by revisions.
+const (
+	revisionCreate  = "create"
+	revisionUpdate  = "update"
+	revisionRemove  = "remove"
+	revisionRevert  = "revert"
+	revisionRestore = "restore"
+)
+
+// TranslationHistory returns the revisions of a translation, newest first.
+func TranslationHistory(db *gorm.DB, ctx context.Context, translationID string) ([]*model.TranslationRevision, error) {
+	intID, err := strconv.Atoi(translationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	revisions, err := RevisionsByTranslation(db, ctx, []uint{uint(intID)})
+	if err != nil {
+		return nil, err
+	}
+	if revisions[uint(intID)] == nil {
+		return []*model.TranslationRevision{}, nil
+	}
+
+	return revisions[uint(intID)], nil
+}
+
+// RevertTranslation restores a translation, including its examples, to the
+// snapshot taken right after the given revision. A removed translation is
+// taken out of the trash, or recreated under its previous id once it has been
+// purged.
+func RevertTranslation(db *gorm.DB, ctx context.Context, id string, revisionID string) (*model.Translation, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+	intRevisionID, err := strconv.Atoi(revisionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	var revision gormModels.TranslationRevision
+	if err := transaction.
+		Where("translation_id = ?", intID).
+		First(&revision, intRevisionID).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch revision: %w", err)
+	}
+	if revision.After == nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("revision %s removed the translation, revert to an earlier one", revisionID)
+	}
+
+	var snapshot translationSnapshot
+	if err := json.Unmarshal([]byte(*revision.After), &snapshot); err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to read revision: %w", err)
+	}
+
+	var translation gormModels.Translation
+	err = transaction.
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&translation, intID).Error
+	purged := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !purged {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch translation: %w", err)
+	}
+
+	before, err := loadSnapshot(transaction, uint(intID))
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	source, err := upsertLexeme(transaction, snapshot.Source.Language, snapshot.Source.Word)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+	target, err := upsertLexeme(transaction, snapshot.Target.Language, snapshot.Target.Word)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := checkTranslationConflict(transaction, uint(intID), source, target); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	// Clients saw a translation in the trash as removed, so bringing it back
+	// is announced as a creation.
+	kind := events.TranslationUpdated
+	if purged || translation.DeletedAt.Valid {
+		kind = events.TranslationCreated
+	}
+
+	var replaced []uint
+	if purged {
+		translation = gormModels.Translation{
+			ID:             uint(intID),
+			SourceLexemeID: source.ID,
+			TargetLexemeID: target.ID,
+			CreatedAt:      snapshot.CreatedAt,
+			UpdatedAt:      time.Now(),
+		}
+		err = transaction.Omit(clause.Associations).Create(&translation).Error
+	} else {
+		if translation.SourceLexemeID != source.ID {
+			replaced = append(replaced, translation.SourceLexemeID)
+		}
+		if translation.TargetLexemeID != target.ID {
+			replaced = append(replaced, translation.TargetLexemeID)
+		}
+		translation.SourceLexemeID = source.ID
+		translation.TargetLexemeID = target.ID
+		translation.DeletedAt = gorm.DeletedAt{}
+		translation.UpdatedAt = time.Now()
+		err = transaction.Unscoped().Omit(clause.Associations).Save(&translation).Error
+	}
+	if err != nil {
+		transaction.Rollback()
+		if isUniqueViolation(err) {
+			return nil, translationConflict(source, target)
+		}
+		return nil, fmt.Errorf("failed to save translation: %w", err)
+	}
+
+	if err := transaction.
+		Where("translation_id = ?", translation.ID).
+		Delete(&gormModels.Example{}).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to delete examples: %w", err)
+	}
+	for position, sentence := range snapshot.Examples {
+		example := gormModels.Example{
+			Sentence:      sentence,
+			TranslationID: translation.ID,
+			Position:      position,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if err := transaction.Create(&example).Error; err != nil {
+			transaction.Rollback()
+			return nil, fmt.Errorf("failed to create example: %w", err)
+		}
+	}
+
+	if err := removeOrphanedLexemes(transaction, replaced...); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := recordRevision(transaction, revisionRevert, translation.ID, before); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+	publish(db, kind, translation.ID)
+
+	return findTranslation(db.WithContext(ctx), translation.ID)
+}
+
+// recordRevision appends a revision of a translation changed within
+// transaction. before is the snapshot taken ahead of the change, nil when the
+// translation was created, while the snapshot after it is read back from the
+// transaction. The actor is the user of the transaction's context.
+func recordRevision(transaction *gorm.DB, action string, translationID uint, before *translationSnapshot) error {
+	after, err := loadSnapshot(transaction, translationID)
+	if err != nil {
+		return err
+	}
+
+	revision := gormModels.TranslationRevision{
+		TranslationID: translationID,
+		Action:        action,
+		CreatedAt:     time.Now(),
+	}
+	if user := auth.UserFromContext(transaction.Statement.Context); user != nil {
+		revision.ActorID = &user.ID
+	}
+	if revision.Before, err = marshalSnapshot(before); err != nil {
+		return err
+	}
+	if revision.After, err = marshalSnapshot(after); err != nil {
+		return err
+	}
+
+	if err := transaction.Create(&revision).Error; err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return nil
+}
+
+// loadSnapshot returns the current snapshot of a translation, or nil if it
+// does not exist.
+func loadSnapshot(transaction *gorm.DB, translationID uint) (*translationSnapshot, error) {
+	var translation gormModels.Translation
+	err := transaction.Scopes(preloadSnapshot).First(&translation, translationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch translation: %w", err)
+	}
+
+	snapshot := snapshotTranslation(translation)
+	return &snapshot, nil
+}
+
+func marshalSnapshot(snapshot *translationSnapshot) (*string, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	encoded := string(data)
+	return &encoded, nil
+}
+
+func convertRevision(revision gormModels.TranslationRevision, actors map[uint]*model.UserProfile) *model.TranslationRevision {
+	result := &model.TranslationRevision{
+		ID:        strconv.Itoa(int(revision.ID)),
+		Action:    model.RevisionAction(strings.ToUpper(revision.Action)),
+		CreatedAt: revision.CreatedAt.String(),
+		Before:    revision.Before,
+		After:     revision.After,
+	}
+	if revision.ActorID != nil {
+		result.Actor = actors[*revision.ActorID]
+	}
+
+	return result
+}
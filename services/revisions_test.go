@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslationHistory(t *testing.T) {
+
+	setupTestDB(t)
+
+	editor := registerTestUser(t, "editor@example.com")
+	ctx := auth.WithUser(context.Background(), &gormModels.User{ID: editor})
+
+	translation, err := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "zamek",
+		EnglishWord: "castle",
+		Examples:    []*model.NewExampleInput{{Sentence: "Zamek stoi na wzgórzu."}},
+	})
+	assert.NoError(t, err, "CreateTranslation should not return an error")
+
+	lock := "lock"
+	_, err = services.UpdateTranslation(testDB, ctx, model.UpdateTranslationInput{ID: translation.ID, EnglishWord: &lock})
+	assert.NoError(t, err, "UpdateTranslation should not return an error")
+
+	_, err = services.AddExample(testDB, context.Background(), translation.ID, "Zamek jest zepsuty.", nil)
+	assert.NoError(t, err, "AddExample should not return an error")
+
+	history, err := services.TranslationHistory(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "TranslationHistory should not return an error")
+	if assert.Equal(t, 3, len(history), "Every change should be recorded") {
+		assert.Equal(t, model.RevisionActionUpdate, history[0].Action, "Newest revision should come first")
+		assert.Nil(t, history[0].Actor, "Changes made without a user should have no actor")
+		assert.Equal(t, model.RevisionActionUpdate, history[1].Action, "Update should be recorded")
+		assert.Contains(t, *history[1].Before, `"word":"castle"`, "Before snapshot should hold the old word")
+		assert.Contains(t, *history[1].After, `"word":"lock"`, "After snapshot should hold the new word")
+		if assert.NotNil(t, history[1].Actor, "Actor should be recorded") {
+			assert.Equal(t, strconv.Itoa(int(editor)), history[1].Actor.ID, "Actor should be recorded")
+		}
+		assert.Equal(t, model.RevisionActionCreate, history[2].Action, "Create should be recorded")
+		assert.Nil(t, history[2].Before, "Create should have no before snapshot")
+	}
+
+	removed, err := services.RemoveTranslation(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+	assert.True(t, removed, "Translation should be removed")
+
+	history, err = services.TranslationHistory(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "History should outlive the translation")
+	if assert.Equal(t, 4, len(history), "Removal should be recorded") {
+		assert.Equal(t, model.RevisionActionRemove, history[0].Action, "Remove should be recorded")
+		assert.Nil(t, history[0].After, "Remove should have no after snapshot")
+	}
+
+	err = testDB.Exec("DELETE FROM translation_revisions").Error
+	assert.Error(t, err, "Revisions should be append-only")
+}
+
+func TestRevertTranslation(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	translation, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "zamek",
+		EnglishWord: "castle",
+		Examples:    []*model.NewExampleInput{{Sentence: "Zamek stoi na wzgórzu."}},
+	})
+	created, _ := services.TranslationHistory(testDB, ctx, translation.ID)
+
+	lock := "lock"
+	_, err := services.UpdateTranslation(testDB, ctx, model.UpdateTranslationInput{
+		ID:               translation.ID,
+		EnglishWord:      &lock,
+		RemoveExampleIds: []string{expand(t, translation).Examples[0].ID},
+	})
+	assert.NoError(t, err, "UpdateTranslation should not return an error")
+
+	reverted, err := services.RevertTranslation(testDB, ctx, translation.ID, created[0].ID)
+	assert.NoError(t, err, "RevertTranslation should not return an error")
+	expanded := expand(t, reverted)
+	assert.Equal(t, "castle", expanded.Target.Word, "Word should be restored")
+	if assert.Equal(t, 1, len(expanded.Examples), "Examples should be restored") {
+		assert.Equal(t, "Zamek stoi na wzgórzu.", expanded.Examples[0].Sentence, "Example sentence should be restored")
+	}
+
+	_, err = services.LexemeByWord(testDB, ctx, "en", "lock")
+	assert.Error(t, err, "Lexeme left without translations should be removed")
+
+	_, err = services.RemoveTranslation(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+
+	history, _ := services.TranslationHistory(testDB, ctx, translation.ID)
+	_, err = services.RevertTranslation(testDB, ctx, translation.ID, history[0].ID)
+	assert.Error(t, err, "Reverting to a removal should fail")
+
+	reverted, err = services.RevertTranslation(testDB, ctx, translation.ID, history[1].ID)
+	assert.NoError(t, err, "Removed translation should be recreated")
+	assert.Equal(t, translation.ID, reverted.ID, "Translation should keep its id")
+	assert.Equal(t, "castle", expand(t, reverted).Target.Word, "Word should be restored")
+
+	history, _ = services.TranslationHistory(testDB, ctx, translation.ID)
+	assert.Equal(t, model.RevisionActionRevert, history[0].Action, "Revert should be recorded")
+}
+
+func TestRenameLexemeHistory(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	castle, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "castle"})
+	lock, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "lock"})
+
+	_, err := services.RenameLexeme(testDB, ctx, strconv.Itoa(int(castle.SourceLexemeID)), "zameczek")
+	assert.NoError(t, err, "RenameLexeme should not return an error")
+
+	revisions, err := services.RevisionsByTranslation(testDB, ctx, []uint{parseID(t, castle.ID), parseID(t, lock.ID)})
+	assert.NoError(t, err, "RevisionsByTranslation should not return an error")
+	for _, translation := range []*model.Translation{castle, lock} {
+		history := revisions[parseID(t, translation.ID)]
+		if assert.Equal(t, 2, len(history), "Rename should be in the history of every translation") {
+			assert.Equal(t, model.RevisionActionUpdate, history[0].Action, "Rename should be recorded as an update")
+			assert.Contains(t, *history[0].Before, `"word":"zamek"`, "Before snapshot should hold the old word")
+			assert.Contains(t, *history[0].After, `"word":"zameczek"`, "After snapshot should hold the new word")
+		}
+	}
+}
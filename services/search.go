@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSearchResults = 100
+	// Well below the pg_trgm default of 0.3 so that typos in short words,
+	// which share only a few trigrams, still produce candidates.
+	fuzzySimilarityThreshold = 0.15
+)
+
+type searchHit struct {
+	TranslationID uint
+	Score         float64
+	Distance      int
+}
+
+func SearchTranslations(db *gorm.DB, ctx context.Context, query string, language *string, mode *model.SearchMode, limit *int32) ([]*model.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	searchMode := model.SearchModeSubstring
+	if mode != nil {
+		searchMode = *mode
+	}
+
+	maxResults := 20
+	if limit != nil {
+		maxResults = int(*limit)
+	}
+	if maxResults < 1 || maxResults > maxSearchResults {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxSearchResults)
+	}
+
+	lexemeJoin := "l.id = t.%s AND t.deleted_at IS NULL"
+	if language != nil {
+		if err := checkLanguage(db.WithContext(ctx), *language); err != nil {
+			return nil, err
+		}
+		lexemeJoin += " AND l.language_code = @language"
+	}
+	selects := []string{
+		searchSelect(searchMode, "l.word",
+			"translations t JOIN lexemes l ON "+fmt.Sprintf(lexemeJoin, "source_lexeme_id")),
+		searchSelect(searchMode, "l.word",
+			"translations t JOIN lexemes l ON "+fmt.Sprintf(lexemeJoin, "target_lexeme_id")),
+	}
+
+	order := "score DESC, translation_id"
+	if searchMode == model.SearchModeFuzzy {
+		order = "distance, score DESC, translation_id"
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT translation_id, MAX(score) AS score, MIN(distance) AS distance
+		FROM (%s) matches
+		GROUP BY translation_id
+		ORDER BY %s
+		LIMIT @limit`,
+		strings.Join(selects, " UNION ALL "), order,
+	)
+	args := map[string]interface{}{
+		"query":   query,
+		"pattern": searchPattern(searchMode, query),
+		"limit":   maxResults,
+	}
+	if language != nil {
+		args["language"] = *language
+	}
+
+	var hits []searchHit
+	err := db.WithContext(ctx).Transaction(func(transaction *gorm.DB) error {
+		if searchMode == model.SearchModeFuzzy {
+			if err := transaction.
+				Exec(fmt.Sprintf("SET LOCAL pg_trgm.similarity_threshold = %v", fuzzySimilarityThreshold)).
+				Error; err != nil {
+				return err
+			}
+		}
+		return transaction.Raw(sql, args).Scan(&hits).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search translations: %w", err)
+	}
+
+	if len(hits) == 0 {
+		return []*model.SearchResult{}, nil
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.TranslationID)
+	}
+
+	var translations []gormModels.Translation
+	if err := db.WithContext(ctx).Find(&translations, ids).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]gormModels.Translation, len(translations))
+	for _, translation := range translations {
+		byID[translation.ID] = translation
+	}
+
+	result := make([]*model.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		translation, ok := byID[hit.TranslationID]
+		if !ok {
+			continue
+		}
+		result = append(result, &model.SearchResult{
+			Translation: convertTranslation(translation),
+			Score:       hit.Score,
+		})
+	}
+
+	return result, nil
+}
+
+// searchSelect builds the query matching column against the search term.
+// Both sides are lowercased and stripped of diacritics, which is what the
+// trigram indexes from the search migration are built on.
+func searchSelect(mode model.SearchMode, column string, from string) string {
+	normalizedColumn := fmt.Sprintf("f_unaccent(lower(%s))", column)
+	normalizedQuery := "f_unaccent(lower(@query))"
+	distance := fmt.Sprintf("levenshtein(%s, %s)", normalizedColumn, normalizedQuery)
+
+	var condition, score string
+	switch mode {
+	case model.SearchModeFuzzy:
+		condition = fmt.Sprintf("%s %% %s", normalizedColumn, normalizedQuery)
+		score = fmt.Sprintf("1 - %s::float / greatest(length(%s), length(%s), 1)",
+			distance, normalizedColumn, normalizedQuery)
+	default:
+		condition = fmt.Sprintf("%s LIKE f_unaccent(lower(@pattern))", normalizedColumn)
+		score = fmt.Sprintf("similarity(%s, %s)", normalizedColumn, normalizedQuery)
+	}
+
+	return fmt.Sprintf("SELECT t.id AS translation_id, %s AS score, %s AS distance FROM %s WHERE %s",
+		score, distance, from, condition)
+}
+
+func searchPattern(mode model.SearchMode, query string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+
+	switch mode {
+	case model.SearchModePrefix:
+		return escaped + "%"
+	case model.SearchModeSubstring:
+		return "%" + escaped + "%"
+	default:
+		return escaped
+	}
+}
@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func createSearchFixtures(t *testing.T, ctx context.Context) {
+	inputTranslations := []model.NewTranslationInput{
+		{PolishWord: "pisać", EnglishWord: "write"},
+		{PolishWord: "przepisać", EnglishWord: "rewrite"},
+		{PolishWord: "pić", EnglishWord: "drink"},
+		{PolishWord: "łódź", EnglishWord: "boat"},
+	}
+	for _, input := range inputTranslations {
+		_, err := services.CreateTranslation(testDB, ctx, input)
+		assert.NoError(t, err, "CreateTranslation should not return an error")
+	}
+}
+
+func TestSearchTranslationsPrefix(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	createSearchFixtures(t, ctx)
+
+	mode := model.SearchModePrefix
+	results, err := services.SearchTranslations(testDB, ctx, "wri", nil, &mode, nil)
+	assert.NoError(t, err, "SearchTranslations should not return an error")
+	assert.Equal(t, 1, len(results), "Only words starting with the query should match")
+	assert.Equal(t, "write", expand(t, results[0].Translation).EnglishWord, "EnglishWord should match")
+}
+
+func TestSearchTranslationsSubstring(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	createSearchFixtures(t, ctx)
+
+	mode := model.SearchModeSubstring
+	results, err := services.SearchTranslations(testDB, ctx, "write", nil, &mode, nil)
+	assert.NoError(t, err, "SearchTranslations should not return an error")
+	assert.Equal(t, 2, len(results), "Both write and rewrite should match")
+	assert.Equal(t, "write", expand(t, results[0].Translation).EnglishWord, "Exact match should be ranked first")
+	assert.Greater(t, results[0].Score, results[1].Score, "Scores should be descending")
+}
+
+func TestSearchTranslationsIgnoresDiacritics(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	createSearchFixtures(t, ctx)
+
+	language := "pl"
+	mode := model.SearchModePrefix
+	results, err := services.SearchTranslations(testDB, ctx, "pisac", &language, &mode, nil)
+	assert.NoError(t, err, "SearchTranslations should not return an error")
+	assert.Equal(t, 1, len(results), "pisac should find pisać")
+	assert.Equal(t, "pisać", expand(t, results[0].Translation).PolishWord.Word, "PolishWord should match")
+
+	results, err = services.SearchTranslations(testDB, ctx, "lodz", &language, &mode, nil)
+	assert.NoError(t, err, "SearchTranslations should not return an error")
+	assert.Equal(t, 1, len(results), "lodz should find łódź")
+}
+
+func TestSearchTranslationsFuzzy(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	createSearchFixtures(t, ctx)
+
+	language := "en"
+	mode := model.SearchModeFuzzy
+	results, err := services.SearchTranslations(testDB, ctx, "wrtie", &language, &mode, nil)
+	assert.NoError(t, err, "SearchTranslations should not return an error")
+	assert.NotEmpty(t, results, "Typo should still find a match")
+	assert.Equal(t, "write", expand(t, results[0].Translation).EnglishWord, "Closest word should be ranked first")
+}
+
+func TestSearchTranslationsInvalidLanguage(t *testing.T) {
+
+	setupTestDB(t)
+
+	language := "xx"
+	_, err := services.SearchTranslations(testDB, context.Background(), "write", &language, nil, nil)
+	assert.Error(t, err, "Unsupported language should return an error")
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/srs"
+	"gorm.io/gorm"
+)
+
+// Service is what the GraphQL layer needs from this package. Its methods are
+// the functions of the same name bound to a database, and those changing data
+// run again when their transaction fails with a serialization failure or a
+// deadlock.
+type Service interface {
+	AddTranslation(ctx context.Context, input model.TranslationInput) (*model.Translation, error)
+	CreateTranslation(ctx context.Context, input model.NewTranslationInput) (*model.Translation, error)
+	RemoveTranslation(ctx context.Context, id string) (bool, error)
+	UpdateTranslation(ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error)
+	Translations(ctx context.Context) ([]*model.Translation, error)
+	TranslationsConnection(ctx context.Context, first *int32, after *string, last *int32, before *string) (*model.TranslationConnection, error)
+	Translation(ctx context.Context, id string) (*model.Translation, error)
+
+	DeletedTranslations(ctx context.Context, page *model.PageInput) (*model.TranslationConnection, error)
+	RestoreTranslation(ctx context.Context, id string) (*model.Translation, error)
+
+	TranslationHistory(ctx context.Context, translationID string) ([]*model.TranslationRevision, error)
+	RevertTranslation(ctx context.Context, id string, revisionID string) (*model.Translation, error)
+
+	AddExample(ctx context.Context, translationID string, sentence string, position *int32) (*model.Example, error)
+	UpdateExample(ctx context.Context, id string, sentence string) (*model.Example, error)
+	RemoveExample(ctx context.Context, id string) (bool, error)
+	ReorderExamples(ctx context.Context, translationID string, exampleIDs []string) ([]*model.Example, error)
+
+	Lexeme(ctx context.Context, id string) (*model.Lexeme, error)
+	LexemeByWord(ctx context.Context, language string, word string) (*model.Lexeme, error)
+	Lexemes(ctx context.Context, filter *model.LexemeFilter, page *model.PageInput) (*model.LexemeConnection, error)
+	RenameLexeme(ctx context.Context, id string, word string) (*model.Lexeme, error)
+	DeleteLexeme(ctx context.Context, id string, cascade bool) (bool, error)
+
+	PolishWord(ctx context.Context, id string) (*model.PolishWord, error)
+	PolishWordByText(ctx context.Context, word string) (*model.PolishWord, error)
+	PolishWords(ctx context.Context, filter *model.PolishWordFilter, page *model.PageInput) (*model.PolishWordConnection, error)
+	RenamePolishWord(ctx context.Context, id string, word string) (*model.PolishWord, error)
+	DeletePolishWord(ctx context.Context, id string, cascade bool) (bool, error)
+
+	Languages(ctx context.Context) ([]*model.Language, error)
+	AddLanguage(ctx context.Context, code string, name string) (*model.Language, error)
+
+	TranslationsByEnglish(ctx context.Context, word string) ([]*model.TranslationGroup, error)
+	TranslationsByPolish(ctx context.Context, word string) ([]*model.TranslationGroup, error)
+
+	SearchTranslations(ctx context.Context, query string, language *string, mode *model.SearchMode, limit *int32) ([]*model.SearchResult, error)
+
+	ImportTranslations(ctx context.Context, file io.Reader, format *model.ImportFormat, dryRun *bool, mode *model.ImportMode) (*model.ImportReport, error)
+
+	DueReviews(ctx context.Context, clock srs.Clock, userID uint, limit *int32) ([]*model.Review, error)
+	SubmitReview(ctx context.Context, clock srs.Clock, userID uint, translationID string, grade int32) (*model.Review, error)
+
+	MyWordLists(ctx context.Context, userID uint) ([]*model.WordList, error)
+	WordList(ctx context.Context, userID uint, id string) (*model.WordList, error)
+	CreateWordList(ctx context.Context, userID uint, input model.NewWordListInput) (*model.WordList, error)
+	UpdateWordList(ctx context.Context, userID uint, input model.UpdateWordListInput) (*model.WordList, error)
+	DeleteWordList(ctx context.Context, userID uint, id string) (bool, error)
+	AddToWordList(ctx context.Context, userID uint, listID string, translationID string, position *int32) (*model.WordList, error)
+	RemoveFromWordList(ctx context.Context, userID uint, listID string, translationID string) (*model.WordList, error)
+	ReorderWordList(ctx context.Context, userID uint, listID string, translationIDs []string) (*model.WordList, error)
+
+	Register(ctx context.Context, email string, password string) (*model.User, error)
+	Login(ctx context.Context, email string, password string) (*model.User, error)
+	SetUserRole(ctx context.Context, id string, role model.Role) (*model.User, error)
+
+	TranslationEvents(ctx context.Context, kind events.Kind, polishWord *string) <-chan *model.Translation
+
+	LexemesByID(ctx context.Context, ids []uint) (map[uint]*model.Lexeme, error)
+	LanguagesByCode(ctx context.Context, codes []string) (map[string]*model.Language, error)
+	TranslationsByID(ctx context.Context, ids []uint) (map[uint]*model.Translation, error)
+	TranslationsByLexeme(ctx context.Context, lexemeIDs []uint) (map[uint][]*model.Translation, error)
+	ExamplesByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.Example, error)
+	RevisionsByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.TranslationRevision, error)
+}
+
+// New returns the Service working on db. Adding, updating, removing and
+// looking up translations goes through the TranslationRepository stored in
+// db, everything else works on db directly.
+func New(db *gorm.DB) Service {
+	return gormService{db: db, translations: NewGormTranslationRepository(db)}
+}
+
+type gormService struct {
+	db           *gorm.DB
+	translations TranslationRepository
+}
+
+func (s gormService) AddTranslation(ctx context.Context, input model.TranslationInput) (*model.Translation, error) {
+	return s.translations.Add(ctx, input)
+}
+
+func (s gormService) CreateTranslation(ctx context.Context, input model.NewTranslationInput) (*model.Translation, error) {
+	return s.translations.Add(ctx, polishEnglishInput(input))
+}
+
+func (s gormService) RemoveTranslation(ctx context.Context, id string) (bool, error) {
+	return s.translations.Remove(ctx, id)
+}
+
+func (s gormService) UpdateTranslation(ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error) {
+	return s.translations.Update(ctx, input)
+}
+
+func (s gormService) Translations(ctx context.Context) ([]*model.Translation, error) {
+	return s.translations.Translations(ctx)
+}
+
+func (s gormService) TranslationsConnection(ctx context.Context, first *int32, after *string, last *int32, before *string) (*model.TranslationConnection, error) {
+	return TranslationsConnection(s.db, ctx, first, after, last, before)
+}
+
+func (s gormService) Translation(ctx context.Context, id string) (*model.Translation, error) {
+	return s.translations.Translation(ctx, id)
+}
+
+func (s gormService) DeletedTranslations(ctx context.Context, page *model.PageInput) (*model.TranslationConnection, error) {
+	return DeletedTranslations(s.db, ctx, page)
+}
+
+func (s gormService) RestoreTranslation(ctx context.Context, id string) (*model.Translation, error) {
+	return retryTransaction(ctx, func() (*model.Translation, error) {
+		return RestoreTranslation(s.db, ctx, id)
+	})
+}
+
+func (s gormService) TranslationHistory(ctx context.Context, translationID string) ([]*model.TranslationRevision, error) {
+	return TranslationHistory(s.db, ctx, translationID)
+}
+
+func (s gormService) RevertTranslation(ctx context.Context, id string, revisionID string) (*model.Translation, error) {
+	return retryTransaction(ctx, func() (*model.Translation, error) {
+		return RevertTranslation(s.db, ctx, id, revisionID)
+	})
+}
+
+func (s gormService) AddExample(ctx context.Context, translationID string, sentence string, position *int32) (*model.Example, error) {
+	return retryTransaction(ctx, func() (*model.Example, error) {
+		return AddExample(s.db, ctx, translationID, sentence, position)
+	})
+}
+
+func (s gormService) UpdateExample(ctx context.Context, id string, sentence string) (*model.Example, error) {
+	return retryTransaction(ctx, func() (*model.Example, error) {
+		return UpdateExample(s.db, ctx, id, sentence)
+	})
+}
+
+func (s gormService) RemoveExample(ctx context.Context, id string) (bool, error) {
+	return retryTransaction(ctx, func() (bool, error) {
+		return RemoveExample(s.db, ctx, id)
+	})
+}
+
+func (s gormService) ReorderExamples(ctx context.Context, translationID string, exampleIDs []string) ([]*model.Example, error) {
+	return retryTransaction(ctx, func() ([]*model.Example, error) {
+		return ReorderExamples(s.db, ctx, translationID, exampleIDs)
+	})
+}
+
+func (s gormService) Lexeme(ctx context.Context, id string) (*model.Lexeme, error) {
+	return Lexeme(s.db, ctx, id)
+}
+
+func (s gormService) LexemeByWord(ctx context.Context, language string, word string) (*model.Lexeme, error) {
+	return s.translations.LexemeByWord(ctx, language, word)
+}
+
+func (s gormService) Lexemes(ctx context.Context, filter *model.LexemeFilter, page *model.PageInput) (*model.LexemeConnection, error) {
+	return Lexemes(s.db, ctx, filter, page)
+}
+
+func (s gormService) RenameLexeme(ctx context.Context, id string, word string) (*model.Lexeme, error) {
+	return retryTransaction(ctx, func() (*model.Lexeme, error) {
+		return RenameLexeme(s.db, ctx, id, word)
+	})
+}
+
+func (s gormService) DeleteLexeme(ctx context.Context, id string, cascade bool) (bool, error) {
+	return retryTransaction(ctx, func() (bool, error) {
+		return DeleteLexeme(s.db, ctx, id, cascade)
+	})
+}
+
+func (s gormService) PolishWord(ctx context.Context, id string) (*model.PolishWord, error) {
+	return PolishWord(s.db, ctx, id)
+}
+
+func (s gormService) PolishWordByText(ctx context.Context, word string) (*model.PolishWord, error) {
+	return PolishWordByText(s.db, ctx, word)
+}
+
+func (s gormService) PolishWords(ctx context.Context, filter *model.PolishWordFilter, page *model.PageInput) (*model.PolishWordConnection, error) {
+	return PolishWords(s.db, ctx, filter, page)
+}
+
+func (s gormService) RenamePolishWord(ctx context.Context, id string, word string) (*model.PolishWord, error) {
+	return retryTransaction(ctx, func() (*model.PolishWord, error) {
+		return RenamePolishWord(s.db, ctx, id, word)
+	})
+}
+
+func (s gormService) DeletePolishWord(ctx context.Context, id string, cascade bool) (bool, error) {
+	return retryTransaction(ctx, func() (bool, error) {
+		return DeletePolishWord(s.db, ctx, id, cascade)
+	})
+}
+
+func (s gormService) Languages(ctx context.Context) ([]*model.Language, error) {
+	return Languages(s.db, ctx)
+}
+
+func (s gormService) AddLanguage(ctx context.Context, code string, name string) (*model.Language, error) {
+	return AddLanguage(s.db, ctx, code, name)
+}
+
+func (s gormService) TranslationsByEnglish(ctx context.Context, word string) ([]*model.TranslationGroup, error) {
+	return TranslationsByEnglish(s.db, ctx, word)
+}
+
+func (s gormService) TranslationsByPolish(ctx context.Context, word string) ([]*model.TranslationGroup, error) {
+	return TranslationsByPolish(s.db, ctx, word)
+}
+
+func (s gormService) SearchTranslations(ctx context.Context, query string, language *string, mode *model.SearchMode, limit *int32) ([]*model.SearchResult, error) {
+	return SearchTranslations(s.db, ctx, query, language, mode, limit)
+}
+
+func (s gormService) ImportTranslations(ctx context.Context, file io.Reader, format *model.ImportFormat, dryRun *bool, mode *model.ImportMode) (*model.ImportReport, error) {
+	// The file is read up front, since a retried import has to read it again.
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return retryTransaction(ctx, func() (*model.ImportReport, error) {
+		return ImportTranslations(s.db, ctx, bytes.NewReader(content), format, dryRun, mode)
+	})
+}
+
+func (s gormService) DueReviews(ctx context.Context, clock srs.Clock, userID uint, limit *int32) ([]*model.Review, error) {
+	return DueReviews(s.db, ctx, clock, userID, limit)
+}
+
+func (s gormService) SubmitReview(ctx context.Context, clock srs.Clock, userID uint, translationID string, grade int32) (*model.Review, error) {
+	return retryTransaction(ctx, func() (*model.Review, error) {
+		return SubmitReview(s.db, ctx, clock, userID, translationID, grade)
+	})
+}
+
+func (s gormService) MyWordLists(ctx context.Context, userID uint) ([]*model.WordList, error) {
+	return MyWordLists(s.db, ctx, userID)
+}
+
+func (s gormService) WordList(ctx context.Context, userID uint, id string) (*model.WordList, error) {
+	return WordList(s.db, ctx, userID, id)
+}
+
+func (s gormService) CreateWordList(ctx context.Context, userID uint, input model.NewWordListInput) (*model.WordList, error) {
+	return retryTransaction(ctx, func() (*model.WordList, error) {
+		return CreateWordList(s.db, ctx, userID, input)
+	})
+}
+
+func (s gormService) UpdateWordList(ctx context.Context, userID uint, input model.UpdateWordListInput) (*model.WordList, error) {
+	return retryTransaction(ctx, func() (*model.WordList, error) {
+		return UpdateWordList(s.db, ctx, userID, input)
+	})
+}
+
+func (s gormService) DeleteWordList(ctx context.Context, userID uint, id string) (bool, error) {
+	return retryTransaction(ctx, func() (bool, error) {
+		return DeleteWordList(s.db, ctx, userID, id)
+	})
+}
+
+func (s gormService) AddToWordList(ctx context.Context, userID uint, listID string, translationID string, position *int32) (*model.WordList, error) {
+	return retryTransaction(ctx, func() (*model.WordList, error) {
+		return AddToWordList(s.db, ctx, userID, listID, translationID, position)
+	})
+}
+
+func (s gormService) RemoveFromWordList(ctx context.Context, userID uint, listID string, translationID string) (*model.WordList, error) {
+	return retryTransaction(ctx, func() (*model.WordList, error) {
+		return RemoveFromWordList(s.db, ctx, userID, listID, translationID)
+	})
+}
+
+func (s gormService) ReorderWordList(ctx context.Context, userID uint, listID string, translationIDs []string) (*model.WordList, error) {
+	return retryTransaction(ctx, func() (*model.WordList, error) {
+		return ReorderWordList(s.db, ctx, userID, listID, translationIDs)
+	})
+}
+
+func (s gormService) Register(ctx context.Context, email string, password string) (*model.User, error) {
+	return retryTransaction(ctx, func() (*model.User, error) {
+		return Register(s.db, ctx, email, password)
+	})
+}
+
+func (s gormService) Login(ctx context.Context, email string, password string) (*model.User, error) {
+	return Login(s.db, ctx, email, password)
+}
+
+func (s gormService) SetUserRole(ctx context.Context, id string, role model.Role) (*model.User, error) {
+	return retryTransaction(ctx, func() (*model.User, error) {
+		return SetUserRole(s.db, ctx, id, role)
+	})
+}
+
+func (s gormService) TranslationEvents(ctx context.Context, kind events.Kind, polishWord *string) <-chan *model.Translation {
+	return TranslationEvents(s.db, ctx, kind, polishWord)
+}
+
+func (s gormService) LexemesByID(ctx context.Context, ids []uint) (map[uint]*model.Lexeme, error) {
+	return s.translations.LexemesByID(ctx, ids)
+}
+
+func (s gormService) LanguagesByCode(ctx context.Context, codes []string) (map[string]*model.Language, error) {
+	return LanguagesByCode(s.db, ctx, codes)
+}
+
+func (s gormService) TranslationsByID(ctx context.Context, ids []uint) (map[uint]*model.Translation, error) {
+	return TranslationsByID(s.db, ctx, ids)
+}
+
+func (s gormService) TranslationsByLexeme(ctx context.Context, lexemeIDs []uint) (map[uint][]*model.Translation, error) {
+	return TranslationsByLexeme(s.db, ctx, lexemeIDs)
+}
+
+func (s gormService) ExamplesByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.Example, error) {
+	return s.translations.ExamplesByTranslation(ctx, translationIDs)
+}
+
+func (s gormService) RevisionsByTranslation(ctx context.Context, translationIDs []uint) (map[uint][]*model.TranslationRevision, error) {
+	return RevisionsByTranslation(s.db, ctx, translationIDs)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	database "github.com/pgrzankowski/dictionary-app/db"
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+)
+
+// TranslationEvents streams the translations affected by events of the given
+// kind until ctx is done. When polishWord is set, only translations from or
+// into that Polish word are sent. Removed translations are sent as they were
+// when they went to the trash.
+func TranslationEvents(db *gorm.DB, ctx context.Context, kind events.Kind, polishWord *string) <-chan *model.Translation {
+	subscription := events.Subscribe(ctx)
+	result := make(chan *model.Translation)
+
+	go func() {
+		defer close(result)
+
+		for event := range subscription {
+			if event.Kind != kind {
+				continue
+			}
+
+			translation, err := findEventTranslation(db.WithContext(ctx), event)
+			if err != nil {
+				log.Printf("failed to load translation %d for %s event: %v", event.TranslationID, event.Kind, err)
+				continue
+			}
+			if polishWord != nil && !translatesPolishWord(translation, *polishWord) {
+				continue
+			}
+
+			select {
+			case result <- convertTranslation(translation):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return result
+}
+
+// publish announces changes to translations once they are committed, to the
+// local subscribers right away and to those of other instances through the
+// database.
+func publish(db *gorm.DB, kind events.Kind, translationIDs ...uint) {
+	for _, translationID := range translationIDs {
+		events.Publish(events.Event{Kind: kind, TranslationID: translationID})
+	}
+
+	if err := database.Notify(db, kind, translationIDs...); err != nil {
+		log.Printf("failed to share %s events with other instances: %v", kind, err)
+	}
+}
+
+// findEventTranslation loads the translation of event together with its
+// lexemes, which the filter on the Polish word needs.
+func findEventTranslation(db *gorm.DB, event events.Event) (gormModels.Translation, error) {
+	if event.Kind == events.TranslationRemoved {
+		db = db.Unscoped()
+	}
+
+	var translation gormModels.Translation
+	err := db.
+		Preload("SourceLexeme").
+		Preload("TargetLexeme").
+		First(&translation, event.TranslationID).Error
+
+	return translation, err
+}
+
+func translatesPolishWord(translation gormModels.Translation, word string) bool {
+	for _, lexeme := range []gormModels.Lexeme{translation.SourceLexeme, translation.TargetLexeme} {
+		if lexeme.LanguageCode == polishLanguageCode && lexeme.Word == word {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveTranslation(t *testing.T, translations <-chan *model.Translation) *model.Translation {
+	select {
+	case translation := <-translations:
+		return translation
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a translation")
+		return nil
+	}
+}
+
+func TestTranslationEvents(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	zamek := "zamek"
+	created := services.TranslationEvents(testDB, ctx, events.TranslationCreated, nil)
+	filtered := services.TranslationEvents(testDB, ctx, events.TranslationCreated, &zamek)
+	updated := services.TranslationEvents(testDB, ctx, events.TranslationUpdated, nil)
+	removed := services.TranslationEvents(testDB, ctx, events.TranslationRemoved, &zamek)
+
+	write, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	castle, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "castle"})
+
+	assert.Equal(t, write.ID, receiveTranslation(t, created).ID, "Created translations should be sent in order")
+	assert.Equal(t, castle.ID, receiveTranslation(t, created).ID, "Created translations should be sent in order")
+	assert.Equal(t, castle.ID, receiveTranslation(t, filtered).ID, "Only translations of the Polish word should be sent")
+
+	_, err := services.AddExample(testDB, ctx, castle.ID, "Zamek stoi na wzgórzu.", nil)
+	assert.NoError(t, err, "AddExample should not return an error")
+	translation := receiveTranslation(t, updated)
+	assert.Equal(t, castle.ID, translation.ID, "Changing examples should update the translation")
+	assert.Equal(t, 1, len(expand(t, translation).Examples), "Updated translation should be sent as committed")
+
+	_, err = services.RemoveTranslation(testDB, ctx, write.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+	_, err = services.RemoveTranslation(testDB, ctx, castle.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+	translation = receiveTranslation(t, removed)
+	assert.Equal(t, castle.ID, translation.ID, "Removals of other words should be filtered out")
+	assert.NotNil(t, translation.DeletedAt, "Removed translation should be sent from the trash")
+
+	select {
+	case translation := <-filtered:
+		t.Errorf("unexpected translation %s", translation.ID)
+	default:
+	}
+
+	cancel()
+	select {
+	case _, ok := <-created:
+		assert.False(t, ok, "Channel should be closed once the subscription ends")
+	case <-time.After(time.Second):
+		t.Fatal("Channel should be closed once the subscription ends")
+	}
+}
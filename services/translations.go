@@ -6,85 +6,50 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/pgrzankowski/dictionary-app/events"
 	"github.com/pgrzankowski/dictionary-app/graph/model"
 	gormModels "github.com/pgrzankowski/dictionary-app/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-func CreateTranslation(db *gorm.DB, ctx context.Context, input model.NewTranslationInput) (*model.Translation, error) {
-	transaction := db.Begin()
+const (
+	polishLanguageCode  = "pl"
+	englishLanguageCode = "en"
+)
+
+func AddTranslation(db *gorm.DB, ctx context.Context, input model.TranslationInput) (*model.Translation, error) {
+	transaction := db.WithContext(ctx).Begin()
 	if transaction.Error != nil {
 		return nil, transaction.Error
 	}
 
-	var polishWord gormModels.PolishWord
-
-	if err := transaction.Clauses(clause.OnConflict{DoNothing: true}).Create(&gormModels.PolishWord{Word: input.PolishWord}).Error; err != nil {
-		transaction.Rollback()
-		return nil, fmt.Errorf("failed to create polish word: %w", err)
-	}
-
-	if err := transaction.Where("word = ?", input.PolishWord).First(&polishWord).Error; err != nil {
+	translation, err := addTranslation(transaction, input)
+	if err != nil {
 		transaction.Rollback()
-		return nil, fmt.Errorf("failed to fetch polish word: %w", err)
+		return nil, err
 	}
 
-	var existingTranslation gormModels.Translation
-	if err := transaction.
-		Where("polish_word_id = ? AND english_word = ?", polishWord.ID, input.EnglishWord).
-		First(&existingTranslation).Error; err == nil {
-		transaction.Rollback()
-		return nil, fmt.Errorf("translation for polish word '%s' with english word '%s' already exists", input.PolishWord, input.EnglishWord)
-	} else if err != gorm.ErrRecordNotFound {
-		transaction.Rollback()
-		return nil, fmt.Errorf("error checking for existing translation: %w", err)
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
 	}
+	publish(db, events.TranslationCreated, translation.ID)
 
-	translation := gormModels.Translation{
-		EnglishWord:  input.EnglishWord,
-		PolishWordID: polishWord.ID,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-	if err := transaction.Create(&translation).Error; err != nil {
-		transaction.Rollback()
-		return nil, fmt.Errorf("failed to create translation: %w", err)
-	}
+	return findTranslation(db.WithContext(ctx), translation.ID)
+}
 
-	for _, exInput := range input.Examples {
-		example := gormModels.Example{
-			Sentence:      exInput.Sentence,
-			TranslationID: translation.ID,
-			CreatedAt:     time.Now(),
-			UpdatedAt:     time.Now(),
-		}
-		if err := transaction.Create(&example).Error; err != nil {
-			transaction.Rollback()
-			return nil, fmt.Errorf("failed to create example: %w", err)
-		}
-		translation.Examples = append(translation.Examples, example)
-	}
+// CreateTranslation adds a Polish→English translation. It predates support
+// for other languages and is kept for existing clients.
+func CreateTranslation(db *gorm.DB, ctx context.Context, input model.NewTranslationInput) (*model.Translation, error) {
+	return AddTranslation(db, ctx, polishEnglishInput(input))
+}
 
-	if err := transaction.Commit().Error; err != nil {
-		return nil, err
+func polishEnglishInput(input model.NewTranslationInput) model.TranslationInput {
+	return model.TranslationInput{
+		Source:   &model.LexemeInput{Language: polishLanguageCode, Word: input.PolishWord},
+		Target:   &model.LexemeInput{Language: englishLanguageCode, Word: input.EnglishWord},
+		Examples: input.Examples,
 	}
-
-	transaction.Model(&translation).Association("PolishWord").Find(&translation.PolishWord)
-
-	return &model.Translation{
-		ID:          strconv.Itoa(int(translation.ID)),
-		EnglishWord: translation.EnglishWord,
-		CreatedAt:   translation.CreatedAt.String(),
-		UpdatedAt:   translation.UpdatedAt.String(),
-		PolishWord: &model.PolishWord{
-			ID:        strconv.Itoa(int(polishWord.ID)),
-			Word:      polishWord.Word,
-			CreatedAt: polishWord.CreatedAt.String(),
-			UpdatedAt: polishWord.UpdatedAt.String(),
-		},
-		Examples: convertExamples(translation.Examples),
-	}, nil
 }
 
 func RemoveTranslation(db *gorm.DB, ctx context.Context, id string) (bool, error) {
@@ -93,77 +58,132 @@ func RemoveTranslation(db *gorm.DB, ctx context.Context, id string) (bool, error
 		return false, fmt.Errorf("invalid id format: %w", err)
 	}
 
-	transaction := db.Begin()
+	transaction := db.WithContext(ctx).Begin()
 	if transaction.Error != nil {
 		return false, transaction.Error
 	}
 
 	var translation gormModels.Translation
 	if err := transaction.
-		Preload("PolishWord").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
 		First(&translation, intID).
 		Error; err != nil {
 		transaction.Rollback()
 		return false, fmt.Errorf("failed to fetch translation: %w", err)
 	}
 
-	polishWordID := translation.PolishWordID
+	before, err := loadSnapshot(transaction, translation.ID)
+	if err != nil {
+		transaction.Rollback()
+		return false, err
+	}
 
-	if err := transaction.
-		Delete(&translation).Error; err != nil {
+	if err := deleteTranslation(transaction, translation); err != nil {
 		transaction.Rollback()
-		return false, fmt.Errorf("failed to delete translation: %w", err)
+		return false, err
 	}
 
-	var translationCount int64
-	if err := transaction.Model(&gormModels.Translation{}).
-		Where("polish_word_id = ?", polishWordID).
-		Count(&translationCount).Error; err != nil {
+	if err := removeOrphanedLexemes(transaction, translation.SourceLexemeID, translation.TargetLexemeID); err != nil {
 		transaction.Rollback()
-		return false, fmt.Errorf("failed to count translations: %w", err)
+		return false, err
 	}
 
-	if translationCount == 0 {
-		if err := transaction.
-			Delete(&gormModels.PolishWord{}, polishWordID).
-			Error; err != nil {
-			transaction.Rollback()
-			return false, fmt.Errorf("failed to delete polish word: %w", err)
-		}
+	if err := recordRevision(transaction, revisionRemove, translation.ID, before); err != nil {
+		transaction.Rollback()
+		return false, err
 	}
 
 	if err := transaction.Commit().Error; err != nil {
 		return false, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	publish(db, events.TranslationRemoved, translation.ID)
 
 	return true, nil
 }
 
+// UpdateTranslation applies the fields set in input and leaves the omitted
+// ones unchanged. Moving a translation to another lexeme removes the previous
+// one once nothing refers to it, like RemoveTranslation does.
 func UpdateTranslation(db *gorm.DB, ctx context.Context, input model.UpdateTranslationInput) (*model.Translation, error) {
 	intID, err := strconv.Atoi(input.ID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid id format: %v", err)
 	}
 
-	transaction := db.Begin()
+	source, target := updatedLexemes(input)
+
+	transaction := db.WithContext(ctx).Begin()
 	if transaction.Error != nil {
 		return nil, transaction.Error
 	}
 
 	var translation gormModels.Translation
 	if err := transaction.
-		Preload("PolishWord").
-		Preload("Examples").
-		First(&translation, intID).
-		Error; err != nil {
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("SourceLexeme").
+		Preload("TargetLexeme").
+		First(&translation, intID).Error; err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	before, err := loadSnapshot(transaction, translation.ID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	previousSourceID := translation.SourceLexemeID
+	previousTargetID := translation.TargetLexemeID
+	if source != nil {
+		translation.SourceLexeme, err = upsertLexeme(transaction, source.Language, source.Word)
+		if err != nil {
+			transaction.Rollback()
+			return nil, err
+		}
+		translation.SourceLexemeID = translation.SourceLexeme.ID
+	}
+	if target != nil {
+		translation.TargetLexeme, err = upsertLexeme(transaction, target.Language, target.Word)
+		if err != nil {
+			transaction.Rollback()
+			return nil, err
+		}
+		translation.TargetLexemeID = translation.TargetLexeme.ID
+	}
+
+	if err := checkTranslationConflict(transaction, translation.ID, translation.SourceLexeme, translation.TargetLexeme); err != nil {
 		transaction.Rollback()
 		return nil, err
 	}
 
-	translation.EnglishWord = *input.EnglishWord
 	translation.UpdatedAt = time.Now()
+	if err := transaction.Omit(clause.Associations).Save(&translation).Error; err != nil {
+		transaction.Rollback()
+		if isUniqueViolation(err) {
+			return nil, translationConflict(translation.SourceLexeme, translation.TargetLexeme)
+		}
+		return nil, err
+	}
+
+	if err := patchExamples(transaction, translation.ID, input.RemoveExampleIds, input.AddExamples); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	var replaced []uint
+	if translation.SourceLexemeID != previousSourceID {
+		replaced = append(replaced, previousSourceID)
+	}
+	if translation.TargetLexemeID != previousTargetID {
+		replaced = append(replaced, previousTargetID)
+	}
+	if err := removeOrphanedLexemes(transaction, replaced...); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
 
-	if err := transaction.Save(&translation).Error; err != nil {
+	if err := recordRevision(transaction, revisionUpdate, translation.ID, before); err != nil {
 		transaction.Rollback()
 		return nil, err
 	}
@@ -171,93 +191,196 @@ func UpdateTranslation(db *gorm.DB, ctx context.Context, input model.UpdateTrans
 	if err := transaction.Commit().Error; err != nil {
 		return nil, err
 	}
+	publish(db, events.TranslationUpdated, translation.ID)
+
+	return findTranslation(db.WithContext(ctx), translation.ID)
+}
 
-	updatedTranslation := &model.Translation{
-		ID:          strconv.Itoa(int(translation.ID)),
-		EnglishWord: translation.EnglishWord,
-		CreatedAt:   translation.EnglishWord,
-		UpdatedAt:   translation.UpdatedAt.String(),
-		PolishWord: &model.PolishWord{
-			ID:        strconv.Itoa(int(translation.PolishWord.ID)),
-			Word:      translation.PolishWord.Word,
-			CreatedAt: translation.PolishWord.CreatedAt.String(),
-			UpdatedAt: translation.PolishWord.UpdatedAt.String(),
-		},
-		Examples: convertExamples(translation.Examples),
+// updatedLexemes returns the lexemes input moves a translation to, nil for
+// those it leaves unchanged. The Polish and English words are shorthands kept
+// for existing clients.
+func updatedLexemes(input model.UpdateTranslationInput) (*model.LexemeInput, *model.LexemeInput) {
+	source := input.Source
+	if source == nil && input.PolishWord != nil {
+		source = &model.LexemeInput{Language: polishLanguageCode, Word: *input.PolishWord}
+	}
+	target := input.Target
+	if target == nil && input.EnglishWord != nil {
+		target = &model.LexemeInput{Language: englishLanguageCode, Word: *input.EnglishWord}
 	}
 
-	return updatedTranslation, nil
+	return source, target
 }
 
 func Translations(db *gorm.DB, ctx context.Context) ([]*model.Translation, error) {
 	var translations []gormModels.Translation
-	if err := db.
-		Preload("PolishWord").
-		Preload("Examples").
-		Find(&translations).Error; err != nil {
+	if err := db.WithContext(ctx).Find(&translations).Error; err != nil {
 		return nil, err
 	}
 
 	var result []*model.Translation
 	for _, translation := range translations {
-		result = append(result, &model.Translation{
-			ID:          strconv.Itoa(int(translation.ID)),
-			EnglishWord: translation.EnglishWord,
-			CreatedAt:   translation.CreatedAt.String(),
-			UpdatedAt:   translation.UpdatedAt.String(),
-			PolishWord: &model.PolishWord{
-				ID:        strconv.Itoa(int(translation.PolishWord.ID)),
-				Word:      translation.PolishWord.Word,
-				CreatedAt: translation.PolishWord.CreatedAt.String(),
-				UpdatedAt: translation.PolishWord.UpdatedAt.String(),
-			},
-			Examples: convertExamples(translation.Examples),
-		})
+		result = append(result, convertTranslation(translation))
 	}
 
 	return result, nil
 }
 
+func TranslationsConnection(db *gorm.DB, ctx context.Context, first *int32, after *string, last *int32, before *string) (*model.TranslationConnection, error) {
+	translations, pageInfo, err := paginate(
+		db.WithContext(ctx).Model(&gormModels.Translation{}),
+		"translations",
+		pageArgs{First: first, After: after, Last: last, Before: before},
+		translationCursor,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*model.TranslationEdge, 0, len(translations))
+	for _, translation := range translations {
+		edges = append(edges, &model.TranslationEdge{
+			Cursor: encodeCursor(translationCursor(translation)),
+			Node:   convertTranslation(translation),
+		})
+	}
+
+	return &model.TranslationConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
 func Translation(db *gorm.DB, ctx context.Context, id string) (*model.Translation, error) {
 	intID, err := strconv.Atoi(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid id format: %v", err)
 	}
 
+	return findTranslation(db.WithContext(ctx), uint(intID))
+}
+
+// addTranslation creates a translation and its examples within transaction,
+// creating the lexemes on first use.
+func addTranslation(transaction *gorm.DB, input model.TranslationInput) (gormModels.Translation, error) {
 	var translation gormModels.Translation
-	if err := db.
-		Preload("PolishWord").
-		Preload("Examples").
-		First(&translation, intID).Error; err != nil {
+	if input.Source == nil || input.Target == nil {
+		return translation, fmt.Errorf("source and target are required")
+	}
+	sentences, err := checkSentences(input.Examples)
+	if err != nil {
+		return translation, err
+	}
+
+	source, err := upsertLexeme(transaction, input.Source.Language, input.Source.Word)
+	if err != nil {
+		return translation, err
+	}
+
+	target, err := upsertLexeme(transaction, input.Target.Language, input.Target.Word)
+	if err != nil {
+		return translation, err
+	}
+
+	if err := checkTranslationConflict(transaction, 0, source, target); err != nil {
+		return translation, err
+	}
+
+	translation = gormModels.Translation{
+		SourceLexemeID: source.ID,
+		TargetLexemeID: target.ID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if err := transaction.Omit(clause.Associations).Create(&translation).Error; err != nil {
+		if isUniqueViolation(err) {
+			return translation, translationConflict(source, target)
+		}
+		return translation, fmt.Errorf("failed to create translation: %w", err)
+	}
+
+	for position, sentence := range sentences {
+		example := gormModels.Example{
+			Sentence:      sentence,
+			TranslationID: translation.ID,
+			Position:      position,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if err := transaction.Create(&example).Error; err != nil {
+			return translation, fmt.Errorf("failed to create example: %w", err)
+		}
+	}
+
+	if err := recordRevision(transaction, revisionCreate, translation.ID, nil); err != nil {
+		return translation, err
+	}
+
+	return translation, nil
+}
+
+// checkTranslationConflict reports whether a translation other than
+// translationID already connects source with target.
+func checkTranslationConflict(transaction *gorm.DB, translationID uint, source gormModels.Lexeme, target gormModels.Lexeme) error {
+	var existingTranslation gormModels.Translation
+	if err := transaction.
+		Where("source_lexeme_id = ? AND target_lexeme_id = ? AND id <> ?", source.ID, target.ID, translationID).
+		First(&existingTranslation).Error; err == nil {
+		return translationConflict(source, target)
+	} else if err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("error checking for existing translation: %w", err)
+	}
+
+	return nil
+}
+
+func translationConflict(source gormModels.Lexeme, target gormModels.Lexeme) error {
+	return fmt.Errorf("%w: translation from %s word '%s' to %s word '%s' already exists",
+		ErrConflict, source.LanguageCode, source.Word, target.LanguageCode, target.Word)
+}
+
+func findTranslation(db *gorm.DB, id uint) (*model.Translation, error) {
+	var translation gormModels.Translation
+	if err := db.First(&translation, id).Error; err != nil {
 		return nil, err
 	}
 
+	return convertTranslation(translation), nil
+}
+
+func orderExamples(db *gorm.DB) *gorm.DB {
+	return db.Order("examples.position, examples.id")
+}
+
+func translationCursor(translation gormModels.Translation) cursor {
+	return cursor{CreatedAt: translation.CreatedAt, ID: translation.ID}
+}
+
+// convertTranslation leaves out the lexemes and examples, which the GraphQL
+// layer loads for all translations of a response at once.
+func convertTranslation(translation gormModels.Translation) *model.Translation {
 	result := &model.Translation{
-		ID:          strconv.Itoa(int(translation.ID)),
-		EnglishWord: translation.EnglishWord,
-		CreatedAt:   translation.CreatedAt.String(),
-		UpdatedAt:   translation.UpdatedAt.String(),
-		PolishWord: &model.PolishWord{
-			ID:        strconv.Itoa(int(translation.PolishWord.ID)),
-			Word:      translation.PolishWord.Word,
-			CreatedAt: translation.PolishWord.CreatedAt.String(),
-			UpdatedAt: translation.PolishWord.UpdatedAt.String(),
-		},
-		Examples: convertExamples(translation.Examples),
+		ID:             strconv.Itoa(int(translation.ID)),
+		CreatedAt:      translation.CreatedAt.String(),
+		UpdatedAt:      translation.UpdatedAt.String(),
+		SourceLexemeID: translation.SourceLexemeID,
+		TargetLexemeID: translation.TargetLexemeID,
+	}
+	if translation.DeletedAt.Valid {
+		deletedAt := translation.DeletedAt.Time.String()
+		result.DeletedAt = &deletedAt
 	}
 
-	return result, nil
+	return result
 }
 
-func convertExamples(examples []gormModels.Example) []*model.Example {
-	var result []*model.Example
-	for _, ex := range examples {
-		result = append(result, &model.Example{
-			ID:        strconv.Itoa(int(ex.ID)),
-			Sentence:  ex.Sentence,
-			CreatedAt: ex.CreatedAt.String(),
-			UpdatedAt: ex.UpdatedAt.String(),
-		})
+func convertExample(example gormModels.Example) *model.Example {
+	return &model.Example{
+		ID:            strconv.Itoa(int(example.ID)),
+		Sentence:      example.Sentence,
+		Position:      int32(example.Position),
+		CreatedAt:     example.CreatedAt.String(),
+		UpdatedAt:     example.UpdatedAt.String(),
+		TranslationID: example.TranslationID,
 	}
-	return result
 }
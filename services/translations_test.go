@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -13,27 +14,128 @@ import (
 	"github.com/pgrzankowski/dictionary-app/graph/model"
 	"github.com/pgrzankowski/dictionary-app/services"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
+// The test database may also be configured through the environment. Without
+// it the tests needing the database are skipped.
 func init() {
-	if err := godotenv.Load("../.env"); err != nil {
+	if err := godotenv.Load("../.env"); err != nil && !os.IsNotExist(err) {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
 }
 
+var (
+	// testDB is connected to by the first test using it.
+	testDB        *gorm.DB
+	testDBErr     error
+	connectTestDB sync.Once
+)
+
+// setupTestDB connects to the test database and empties it, skipping the test
+// if the database is unavailable.
+func setupTestDB(t *testing.T) {
+	connectTestDB.Do(func() {
+		testDB, testDBErr = db.ConnectTestGORM("services_test")
+	})
+	if testDBErr != nil {
+		t.Skipf("test database unavailable: %v", testDBErr)
+	}
+	clearTestDB(t)
+}
+
 // Clear test db
 func clearTestDB(t *testing.T) {
-	err := db.GormTestDB.Exec("TRUNCATE TABLE examples, translations, polish_words RESTART IDENTITY CASCADE").Error
+	err := testDB.Exec("TRUNCATE TABLE examples, translations, lexemes, users, translation_revisions RESTART IDENTITY CASCADE").Error
 	if err != nil {
 		t.Fatalf("failed to truncate tables: %v", err)
 	}
 }
 
-// Test mutations
-func TestCreateTranslation(t *testing.T) {
+func TestGormTranslationRepository(t *testing.T) {
+	testTranslationRepository(t, func(t *testing.T) services.TranslationRepository {
+		setupTestDB(t)
+		return services.NewGormTranslationRepository(testDB)
+	})
+}
 
-	db.ConnectTestGORM()
-	clearTestDB(t)
+func TestMemoryTranslationRepository(t *testing.T) {
+	testTranslationRepository(t, func(*testing.T) services.TranslationRepository {
+		return services.NewMemoryTranslationRepository()
+	})
+}
+
+// testTranslationRepository runs the tests every TranslationRepository has to
+// pass, each against an empty repository returned by newRepository.
+func testTranslationRepository(t *testing.T, newRepository func(*testing.T) services.TranslationRepository) {
+	tests := []struct {
+		name string
+		test func(*testing.T, services.TranslationRepository)
+	}{
+		{"CreateTranslation", testCreateTranslation},
+		{"CreateTranslationValidation", testCreateTranslationValidation},
+		{"SharedLexemes", testSharedLexemes},
+		{"RemoveTranslation", testRemoveTranslation},
+		{"RemoveTranslationLexemes", testRemoveTranslationLexemes},
+		{"UpdateTranslation", testUpdateTranslation},
+		{"UpdateTranslationWithOnlyID", testUpdateTranslationWithOnlyID},
+		{"UpdateTranslationPolishWord", testUpdateTranslationPolishWord},
+		{"UpdateTranslationConflict", testUpdateTranslationConflict},
+		{"UpdateTranslationExamples", testUpdateTranslationExamples},
+		{"Translations", testTranslations},
+		{"Translation", testTranslation},
+		{"ConcurrentCreateTranslation", testConcurrentCreateTranslation},
+		{"ConcurrentRemoveTranslation", testConcurrentRemoveTranslation},
+		{"RemoveNonExisting", testRemoveNonExisting},
+		{"CreateExisting", testCreateExisting},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t))
+		})
+	}
+}
+
+// polishEnglish is the input CreateTranslation adds for input.
+func polishEnglish(input model.NewTranslationInput) model.TranslationInput {
+	return model.TranslationInput{
+		Source:   &model.LexemeInput{Language: "pl", Word: input.PolishWord},
+		Target:   &model.LexemeInput{Language: "en", Word: input.EnglishWord},
+		Examples: input.Examples,
+	}
+}
+
+// wordsOf returns the source and target words of translation.
+func wordsOf(t *testing.T, repository services.TranslationRepository, translation *model.Translation) (string, string) {
+	t.Helper()
+
+	lexemes, err := repository.LexemesByID(context.Background(), []uint{translation.SourceLexemeID, translation.TargetLexemeID})
+	if err != nil {
+		t.Fatalf("failed to load lexemes: %v", err)
+	}
+	source, target := lexemes[translation.SourceLexemeID], lexemes[translation.TargetLexemeID]
+	if source == nil || target == nil {
+		t.Fatalf("lexemes of translation %s not found", translation.ID)
+	}
+
+	return source.Word, target.Word
+}
+
+// examplesOf returns the examples of translation in order.
+func examplesOf(t *testing.T, repository services.TranslationRepository, translation *model.Translation) []*model.Example {
+	t.Helper()
+
+	examples, err := repository.ExamplesByTranslation(context.Background(), []uint{parseID(t, translation.ID)})
+	if err != nil {
+		t.Fatalf("failed to load examples: %v", err)
+	}
+
+	return examples[parseID(t, translation.ID)]
+}
+
+// Test mutations
+func testCreateTranslation(t *testing.T, repository services.TranslationRepository) {
 
 	input := model.NewTranslationInput{
 		PolishWord:  "pisać",
@@ -44,19 +146,60 @@ func TestCreateTranslation(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	translation, err := services.CreateTranslation(db.GormTestDB, ctx, input)
-	assert.NoError(t, err, "CreateTranslation should not return an error")
+	translation, err := repository.Add(ctx, polishEnglish(input))
+	assert.NoError(t, err, "Add should not return an error")
 	assert.NotNil(t, translation, "translation should not be nil")
-	assert.Equal(t, "write", translation.EnglishWord, "EnglishWord should match")
-	assert.Equal(t, "pisać", translation.PolishWord.Word, "PolishWord should match")
-	assert.Equal(t, 1, len(translation.Examples), "Examples list should have 1 element")
-	assert.Equal(t, "On lubi pisać listy.", translation.Examples[0].Sentence, "Sentence should match")
+	polishWord, englishWord := wordsOf(t, repository, translation)
+	assert.Equal(t, "write", englishWord, "EnglishWord should match")
+	assert.Equal(t, "pisać", polishWord, "PolishWord should match")
+	examples := examplesOf(t, repository, translation)
+	assert.Equal(t, 1, len(examples), "Examples list should have 1 element")
+	assert.Equal(t, "On lubi pisać listy.", examples[0].Sentence, "Sentence should match")
 }
 
-func TestRemoveTranslation(t *testing.T) {
+func testCreateTranslationValidation(t *testing.T, repository services.TranslationRepository) {
 
-	db.ConnectTestGORM()
-	clearTestDB(t)
+	ctx := context.Background()
+
+	_, err := repository.Add(ctx, model.TranslationInput{Source: &model.LexemeInput{Language: "pl", Word: "pisać"}})
+	assert.Error(t, err, "Missing target should return an error")
+
+	_, err = repository.Add(ctx, polishEnglish(model.NewTranslationInput{PolishWord: "  ", EnglishWord: "write"}))
+	assert.Error(t, err, "Empty word should return an error")
+	assert.Contains(t, err.Error(), "word cannot be empty", fmt.Sprintf("expected word cannot be empty, got: %v", err))
+
+	_, err = repository.Add(ctx, model.TranslationInput{
+		Source: &model.LexemeInput{Language: "pl", Word: "pisać"},
+		Target: &model.LexemeInput{Language: "xx", Word: "write"},
+	})
+	assert.Error(t, err, "Unknown language should return an error")
+	assert.Contains(t, err.Error(), "unsupported language", fmt.Sprintf("expected unsupported language, got: %v", err))
+
+	_, err = repository.Add(ctx, polishEnglish(model.NewTranslationInput{
+		PolishWord:  "pisać",
+		EnglishWord: "write",
+		Examples:    []*model.NewExampleInput{{Sentence: "On lubi pisać listy."}, {Sentence: " "}},
+	}))
+	assert.Error(t, err, "Empty sentence should return an error")
+
+	_, err = repository.LexemeByWord(ctx, "pl", "pisać")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Failed additions should not leave lexemes behind")
+}
+
+func testSharedLexemes(t *testing.T, repository services.TranslationRepository) {
+
+	ctx := context.Background()
+	castle, _ := repository.Add(ctx, polishEnglish(model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "castle"}))
+	lock, err := repository.Add(ctx, polishEnglish(model.NewTranslationInput{PolishWord: " zamek ", EnglishWord: "lock"}))
+	assert.NoError(t, err, "Add should not return an error")
+	assert.Equal(t, castle.SourceLexemeID, lock.SourceLexemeID, "Translations of the same word should share its lexeme")
+
+	lexeme, err := repository.LexemeByWord(ctx, "pl", "zamek ")
+	assert.NoError(t, err, "LexemeByWord should not return an error")
+	assert.Equal(t, "zamek", lexeme.Word, "Words should be stored without surrounding whitespace")
+}
+
+func testRemoveTranslation(t *testing.T, repository services.TranslationRepository) {
 
 	input := model.NewTranslationInput{
 		PolishWord:  "pisać",
@@ -67,20 +210,37 @@ func TestRemoveTranslation(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	translation, _ := services.CreateTranslation(db.GormTestDB, ctx, input)
-	removed, err := services.RemoveTranslation(db.GormTestDB, ctx, translation.ID)
-	assert.NoError(t, err, "RemoveTranslation should not return an error")
+	translation, _ := repository.Add(ctx, polishEnglish(input))
+	removed, err := repository.Remove(ctx, translation.ID)
+	assert.NoError(t, err, "Remove should not return an error")
 	assert.NotNil(t, removed, "removed should not be nil")
 	assert.True(t, removed, "removed should be true")
 
-	_, err = services.Translation(db.GormTestDB, ctx, translation.ID)
+	_, err = repository.Translation(ctx, translation.ID)
 	assert.Error(t, err, "Quering deleted translation should return an error")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Error should be not found")
+
+	recreated, err := repository.Add(ctx, polishEnglish(model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"}))
+	assert.NoError(t, err, "Removed translations should not conflict")
+	assert.Empty(t, examplesOf(t, repository, recreated), "Examples should be removed with their translation")
 }
 
-func TestUpdateTranslation(t *testing.T) {
+func testRemoveTranslationLexemes(t *testing.T, repository services.TranslationRepository) {
 
-	db.ConnectTestGORM()
-	clearTestDB(t)
+	ctx := context.Background()
+	castle, _ := repository.Add(ctx, polishEnglish(model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "castle"}))
+	repository.Add(ctx, polishEnglish(model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "lock"}))
+
+	_, err := repository.Remove(ctx, castle.ID)
+	assert.NoError(t, err, "Remove should not return an error")
+
+	_, err = repository.LexemeByWord(ctx, "en", "castle")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Orphaned english word should be removed")
+	_, err = repository.LexemeByWord(ctx, "pl", "zamek")
+	assert.NoError(t, err, "Words that are still translated should be kept")
+}
+
+func testUpdateTranslation(t *testing.T, repository services.TranslationRepository) {
 
 	createInput := model.NewTranslationInput{
 		PolishWord:  "pisać",
@@ -91,7 +251,7 @@ func TestUpdateTranslation(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	translation, _ := services.CreateTranslation(db.GormTestDB, ctx, createInput)
+	translation, _ := repository.Add(ctx, polishEnglish(createInput))
 
 	updatedEnglishWord := "type"
 	updateInput := model.UpdateTranslationInput{
@@ -99,18 +259,122 @@ func TestUpdateTranslation(t *testing.T) {
 		EnglishWord: &updatedEnglishWord,
 	}
 
-	updatedTranslation, err := services.UpdateTranslation(db.GormTestDB, ctx, updateInput)
-	assert.NoError(t, err, "UpdateTranslation should not return an error")
+	updatedTranslation, err := repository.Update(ctx, updateInput)
+	assert.NoError(t, err, "Update should not return an error")
 	assert.NotNil(t, updatedTranslation, "updatedTranslation should not be nil")
-	assert.Equal(t, "type", updatedTranslation.EnglishWord, "EnglishWord should match")
-	assert.Equal(t, "pisać", updatedTranslation.PolishWord.Word, "PolishWord should match")
+	polishWord, englishWord := wordsOf(t, repository, updatedTranslation)
+	assert.Equal(t, "type", englishWord, "EnglishWord should match")
+	assert.Equal(t, "pisać", polishWord, "PolishWord should match")
+	assert.Equal(t, 1, len(examplesOf(t, repository, updatedTranslation)), "Examples should be kept")
 }
 
-// Test queries
-func TestTranslations(t *testing.T) {
+func testUpdateTranslationWithOnlyID(t *testing.T, repository services.TranslationRepository) {
 
-	db.ConnectTestGORM()
-	clearTestDB(t)
+	ctx := context.Background()
+	translation, _ := repository.Add(ctx, polishEnglish(model.NewTranslationInput{
+		PolishWord:  "pisać",
+		EnglishWord: "write",
+	}))
+
+	updatedTranslation, err := repository.Update(ctx, model.UpdateTranslationInput{ID: translation.ID})
+	assert.NoError(t, err, "Update should not return an error")
+	polishWord, englishWord := wordsOf(t, repository, updatedTranslation)
+	assert.Equal(t, "write", englishWord, "EnglishWord should be unchanged")
+	assert.Equal(t, "pisać", polishWord, "PolishWord should be unchanged")
+
+	_, err = repository.Update(ctx, model.UpdateTranslationInput{ID: "999"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Updating a missing translation should return not found")
+}
+
+func testUpdateTranslationPolishWord(t *testing.T, repository services.TranslationRepository) {
+
+	ctx := context.Background()
+	translation, _ := repository.Add(ctx, polishEnglish(model.NewTranslationInput{
+		PolishWord:  "pisac",
+		EnglishWord: "write",
+	}))
+
+	polishWord := "pisać"
+	updatedTranslation, err := repository.Update(ctx, model.UpdateTranslationInput{
+		ID:         translation.ID,
+		PolishWord: &polishWord,
+	})
+	assert.NoError(t, err, "Update should not return an error")
+	updatedPolishWord, englishWord := wordsOf(t, repository, updatedTranslation)
+	assert.Equal(t, "pisać", updatedPolishWord, "PolishWord should match")
+	assert.Equal(t, "write", englishWord, "EnglishWord should be unchanged")
+
+	_, err = repository.LexemeByWord(ctx, "pl", "pisac")
+	assert.Error(t, err, "Orphaned polish word should be removed")
+}
+
+func testUpdateTranslationConflict(t *testing.T, repository services.TranslationRepository) {
+
+	ctx := context.Background()
+	repository.Add(ctx, polishEnglish(model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"}))
+	translation, _ := repository.Add(ctx, polishEnglish(model.NewTranslationInput{
+		PolishWord:  "napisać",
+		EnglishWord: "write",
+		Examples:    []*model.NewExampleInput{{Sentence: "Napisał list."}},
+	}))
+
+	polishWord := "pisać"
+	_, err := repository.Update(ctx, model.UpdateTranslationInput{
+		ID:               translation.ID,
+		PolishWord:       &polishWord,
+		RemoveExampleIds: []string{examplesOf(t, repository, translation)[0].ID},
+	})
+	assert.Error(t, err, "Error should be returned")
+	assert.ErrorIs(t, err, services.ErrConflict, "Error should be a conflict")
+	assert.Contains(t, err.Error(), "already exists", fmt.Sprintf("expected already exists, got: %v", err))
+
+	unchanged, _ := repository.Translation(ctx, translation.ID)
+	unchangedPolishWord, _ := wordsOf(t, repository, unchanged)
+	assert.Equal(t, "napisać", unchangedPolishWord, "Failed update should be rolled back")
+	assert.Equal(t, 1, len(examplesOf(t, repository, unchanged)), "Failed update should keep the examples")
+}
+
+func testUpdateTranslationExamples(t *testing.T, repository services.TranslationRepository) {
+
+	ctx := context.Background()
+	translation, _ := repository.Add(ctx, polishEnglish(model.NewTranslationInput{
+		PolishWord:  "pić",
+		EnglishWord: "drink",
+		Examples: []*model.NewExampleInput{
+			{Sentence: "On lubi pić wodę."},
+			{Sentence: "Lubi też pić kawę."},
+		},
+	}))
+
+	updatedTranslation, err := repository.Update(ctx, model.UpdateTranslationInput{
+		ID:               translation.ID,
+		RemoveExampleIds: []string{examplesOf(t, repository, translation)[0].ID},
+		AddExamples:      []*model.NewExampleInput{{Sentence: " Nie lubi pić herbaty. "}},
+	})
+	assert.NoError(t, err, "Update should not return an error")
+	examples := examplesOf(t, repository, updatedTranslation)
+	assert.Equal(t, []string{"Lubi też pić kawę.", "Nie lubi pić herbaty."}, exampleSentences(examples), "Sentences should match")
+	assert.Equal(t, []int32{0, 1}, []int32{examples[0].Position, examples[1].Position}, "Positions should be renumbered")
+
+	_, err = repository.Update(ctx, model.UpdateTranslationInput{
+		ID:               translation.ID,
+		RemoveExampleIds: []string{"999"},
+	})
+	assert.Error(t, err, "Removing a foreign example should return an error")
+
+	_, err = repository.Update(ctx, model.UpdateTranslationInput{
+		ID:               translation.ID,
+		RemoveExampleIds: []string{examples[0].ID},
+		AddExamples:      []*model.NewExampleInput{{Sentence: "  "}},
+	})
+	if assert.Error(t, err, "Empty sentence should return an error") {
+		assert.Contains(t, err.Error(), "example sentence cannot be empty", fmt.Sprintf("expected example sentence cannot be empty, got: %v", err))
+	}
+	assert.Equal(t, 2, len(examplesOf(t, repository, translation)), "Failed update should keep the examples")
+}
+
+// Test queries
+func testTranslations(t *testing.T, repository services.TranslationRepository) {
 
 	inputTranslations := []model.NewTranslationInput{
 		{
@@ -144,11 +408,11 @@ func TestTranslations(t *testing.T) {
 	var createdTranslations []*model.Translation
 
 	for _, translation := range inputTranslations {
-		createdTranslation, _ := services.CreateTranslation(db.GormTestDB, ctx, translation)
+		createdTranslation, _ := repository.Add(ctx, polishEnglish(translation))
 		createdTranslations = append(createdTranslations, createdTranslation)
 	}
 
-	translations, err := services.Translations(db.GormTestDB, ctx)
+	translations, err := repository.Translations(ctx)
 
 	assert.NoError(t, err, "Translations should not return an error")
 	assert.NotNil(t, translations)
@@ -156,18 +420,67 @@ func TestTranslations(t *testing.T) {
 
 	for ix, translation := range translations {
 		assert.Equal(t, createdTranslations[ix].ID, translation.ID, "ID should match")
-		assert.Equal(t, createdTranslations[ix].PolishWord.Word, translation.PolishWord.Word, "PolishWord should match")
-		assert.Equal(t, createdTranslations[ix].EnglishWord, translation.EnglishWord, "EnglishWord should match")
-		for idx, sentence := range translation.Examples {
-			assert.Equal(t, createdTranslations[ix].Examples[idx].Sentence, sentence.Sentence, "Sentence should match")
+		polishWord, englishWord := wordsOf(t, repository, translation)
+		assert.Equal(t, inputTranslations[ix].PolishWord, polishWord, "PolishWord should match")
+		assert.Equal(t, inputTranslations[ix].EnglishWord, englishWord, "EnglishWord should match")
+		for idx, sentence := range examplesOf(t, repository, translation) {
+			assert.Equal(t, inputTranslations[ix].Examples[idx].Sentence, sentence.Sentence, "Sentence should match")
 		}
 	}
 }
 
-func TestTranslation(t *testing.T) {
+func TestTranslationsConnection(t *testing.T) {
 
-	db.ConnectTestGORM()
-	clearTestDB(t)
+	setupTestDB(t)
+
+	ctx := context.Background()
+
+	englishWords := []string{"write", "drink", "eat", "read", "sleep"}
+	for _, englishWord := range englishWords {
+		_, err := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+			PolishWord:  "słowo",
+			EnglishWord: englishWord,
+		})
+		assert.NoError(t, err, "CreateTranslation should not return an error")
+	}
+
+	pageSize := int32(2)
+	firstPage, err := services.TranslationsConnection(testDB, ctx, &pageSize, nil, nil, nil)
+	assert.NoError(t, err, "TranslationsConnection should not return an error")
+	assert.Equal(t, 2, len(firstPage.Edges), "First page length should match")
+	assert.Equal(t, "write", expand(t, firstPage.Edges[0].Node).EnglishWord, "EnglishWord should match")
+	assert.Equal(t, "drink", expand(t, firstPage.Edges[1].Node).EnglishWord, "EnglishWord should match")
+	assert.True(t, firstPage.PageInfo.HasNextPage, "First page should have a next page")
+	assert.False(t, firstPage.PageInfo.HasPreviousPage, "First page should not have a previous page")
+
+	_, err = services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "słowo",
+		EnglishWord: "swim",
+	})
+	assert.NoError(t, err, "CreateTranslation should not return an error")
+
+	secondPage, err := services.TranslationsConnection(testDB, ctx, &pageSize, firstPage.PageInfo.EndCursor, nil, nil)
+	assert.NoError(t, err, "TranslationsConnection should not return an error")
+	assert.Equal(t, 2, len(secondPage.Edges), "Second page length should match")
+	assert.Equal(t, "eat", expand(t, secondPage.Edges[0].Node).EnglishWord, "Inserted rows should not shift the page")
+	assert.Equal(t, "read", expand(t, secondPage.Edges[1].Node).EnglishWord, "EnglishWord should match")
+	assert.True(t, secondPage.PageInfo.HasPreviousPage, "Second page should have a previous page")
+
+	lastPage, err := services.TranslationsConnection(testDB, ctx, nil, nil, &pageSize, secondPage.PageInfo.StartCursor)
+	assert.NoError(t, err, "TranslationsConnection should not return an error")
+	assert.Equal(t, 2, len(lastPage.Edges), "Backward page length should match")
+	assert.Equal(t, "write", expand(t, lastPage.Edges[0].Node).EnglishWord, "Backward page should keep ascending order")
+	assert.Equal(t, "drink", expand(t, lastPage.Edges[1].Node).EnglishWord, "EnglishWord should match")
+	assert.False(t, lastPage.PageInfo.HasPreviousPage, "Backward page should not have a previous page")
+	assert.True(t, lastPage.PageInfo.HasNextPage, "Backward page should have a next page")
+
+	invalidCursor := "not-a-cursor"
+	_, err = services.TranslationsConnection(testDB, ctx, &pageSize, &invalidCursor, nil, nil)
+	assert.Error(t, err, "Invalid cursor should return an error")
+	assert.Contains(t, err.Error(), "invalid cursor", fmt.Sprintf("expected invalid cursor, got: %v", err))
+}
+
+func testTranslation(t *testing.T, repository services.TranslationRepository) {
 
 	input := model.NewTranslationInput{
 		PolishWord:  "pisać",
@@ -178,25 +491,26 @@ func TestTranslation(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	createdTranslation, _ := services.CreateTranslation(db.GormTestDB, ctx, input)
+	createdTranslation, _ := repository.Add(ctx, polishEnglish(input))
 
-	translation, err := services.Translation(db.GormTestDB, ctx, createdTranslation.ID)
+	translation, err := repository.Translation(ctx, createdTranslation.ID)
 	assert.NoError(t, err, "Translation should not return an error")
 	assert.NotNil(t, translation, "translation should not be nil")
 	assert.Equal(t, createdTranslation.ID, translation.ID, "ID should match")
-	assert.Equal(t, createdTranslation.EnglishWord, translation.EnglishWord, "EnglishWord should match")
-	assert.Equal(t, createdTranslation.PolishWord.ID, translation.PolishWord.ID, "PolishWord.ID should match")
-	assert.Equal(t, createdTranslation.PolishWord.Word, translation.PolishWord.Word, "PolishWord.Word should match")
-	for ix, example := range translation.Examples {
-		assert.Equal(t, createdTranslation.Examples[ix].Sentence, example.Sentence, "Sentences should match")
+	assert.Equal(t, createdTranslation.SourceLexemeID, translation.SourceLexemeID, "PolishWord.ID should match")
+	polishWord, englishWord := wordsOf(t, repository, translation)
+	assert.Equal(t, input.EnglishWord, englishWord, "EnglishWord should match")
+	assert.Equal(t, input.PolishWord, polishWord, "PolishWord.Word should match")
+	for ix, example := range examplesOf(t, repository, translation) {
+		assert.Equal(t, input.Examples[ix].Sentence, example.Sentence, "Sentences should match")
 	}
+
+	_, err = repository.Translation(ctx, "not-an-id")
+	assert.Error(t, err, "Invalid id should return an error")
 }
 
 // Test concurency
-func TestConcurrentCreateTranslation(t *testing.T) {
-
-	db.ConnectTestGORM()
-	clearTestDB(t)
+func testConcurrentCreateTranslation(t *testing.T, repository services.TranslationRepository) {
 
 	input := model.NewTranslationInput{
 		PolishWord:  "pisać",
@@ -226,7 +540,7 @@ func TestConcurrentCreateTranslation(t *testing.T) {
 				cond.Wait()
 			}
 			mu.Unlock()
-			result, err := services.CreateTranslation(db.GormTestDB, ctx, input)
+			result, err := repository.Add(ctx, polishEnglish(input))
 			mu.Lock()
 			results = append(results, result)
 			errors = append(errors, err)
@@ -260,17 +574,16 @@ func TestConcurrentCreateTranslation(t *testing.T) {
 
 	assert.NoError(t, errors[successIdx], "Succeeded creation should not return an error")
 	assert.NotNil(t, results[successIdx], "Succeeded translation should not be nil")
-	assert.Equal(t, "write", results[successIdx].EnglishWord, "EnglishWord should match")
-	assert.Equal(t, "pisać", results[successIdx].PolishWord.Word, "PolishWord should match")
-	assert.Equal(t, 1, len(results[successIdx].Examples), "Examples list should have 1 element")
-	assert.Equal(t, "On lubi pisać listy.", results[successIdx].Examples[0].Sentence, "Sentence should match")
+	polishWord, englishWord := wordsOf(t, repository, results[successIdx])
+	assert.Equal(t, "write", englishWord, "EnglishWord should match")
+	assert.Equal(t, "pisać", polishWord, "PolishWord should match")
+	examples := examplesOf(t, repository, results[successIdx])
+	assert.Equal(t, 1, len(examples), "Examples list should have 1 element")
+	assert.Equal(t, "On lubi pisać listy.", examples[0].Sentence, "Sentence should match")
 
 }
 
-func TestConcurrentRemoveTranslation(t *testing.T) {
-
-	db.ConnectTestGORM()
-	clearTestDB(t)
+func testConcurrentRemoveTranslation(t *testing.T, repository services.TranslationRepository) {
 
 	input := model.NewTranslationInput{
 		PolishWord:  "pisać",
@@ -281,7 +594,7 @@ func TestConcurrentRemoveTranslation(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	createdTranslation, _ := services.CreateTranslation(db.GormTestDB, ctx, input)
+	createdTranslation, _ := repository.Add(ctx, polishEnglish(input))
 	id := createdTranslation.ID
 
 	var mu sync.Mutex
@@ -302,7 +615,7 @@ func TestConcurrentRemoveTranslation(t *testing.T) {
 				cond.Wait()
 			}
 			mu.Unlock()
-			result, err := services.RemoveTranslation(db.GormTestDB, ctx, id)
+			result, err := repository.Remove(ctx, id)
 			mu.Lock()
 			results = append(results, result)
 			errors = append(errors, err)
@@ -333,29 +646,23 @@ func TestConcurrentRemoveTranslation(t *testing.T) {
 	assert.GreaterOrEqual(t, successCount, 1, "Expected at least one successful removal")
 	assert.LessOrEqual(t, errorCount, iterations-1, "Expected the rest of the removals to fail due to non existent")
 
-	_, err := services.Translation(db.GormTestDB, ctx, createdTranslation.ID)
+	_, err := repository.Translation(ctx, createdTranslation.ID)
 	assert.Error(t, err, "Quering deleted translation should return an error")
 }
 
 // Test edge cases and errors
-func TestRemoveNonExisting(t *testing.T) {
-
-	db.ConnectTestGORM()
-	clearTestDB(t)
+func testRemoveNonExisting(t *testing.T, repository services.TranslationRepository) {
 
 	ctx := context.Background()
 
-	result, err := services.RemoveTranslation(db.GormTestDB, ctx, "1")
+	result, err := repository.Remove(ctx, "1")
 
 	assert.Error(t, err, "Error should be returned")
 	assert.Contains(t, err.Error(), "record not found", fmt.Sprintf("expected record not found, got: %v", err))
 	assert.False(t, result, "result should be false")
 }
 
-func TestCreateExisting(t *testing.T) {
-
-	db.ConnectTestGORM()
-	clearTestDB(t)
+func testCreateExisting(t *testing.T, repository services.TranslationRepository) {
 
 	input := model.NewTranslationInput{
 		PolishWord:  "pisać",
@@ -367,9 +674,9 @@ func TestCreateExisting(t *testing.T) {
 
 	ctx := context.Background()
 
-	services.CreateTranslation(db.GormTestDB, ctx, input)
+	repository.Add(ctx, polishEnglish(input))
 
-	_, err := services.CreateTranslation(db.GormTestDB, ctx, input)
+	_, err := repository.Add(ctx, polishEnglish(input))
 
 	assert.Error(t, err, "Error should be returned")
 	assert.Contains(t, err.Error(), "already exists", fmt.Sprintf("expected already exists, got: %v", err))
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/events"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeletedTranslations lists the translations in the trash.
+func DeletedTranslations(db *gorm.DB, ctx context.Context, page *model.PageInput) (*model.TranslationConnection, error) {
+	translations, pageInfo, err := paginate(
+		db.WithContext(ctx).Unscoped().Model(&gormModels.Translation{}).Where("translations.deleted_at IS NOT NULL"),
+		"translations",
+		pageArgsFromInput(page),
+		translationCursor,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*model.TranslationEdge, 0, len(translations))
+	for _, translation := range translations {
+		edges = append(edges, &model.TranslationEdge{
+			Cursor: encodeCursor(translationCursor(translation)),
+			Node:   convertTranslation(translation),
+		})
+	}
+
+	return &model.TranslationConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// RestoreTranslation takes a translation out of the trash together with its
+// examples and lexemes. A lexeme that has been added again in the meantime is
+// used instead of the removed one.
+func RestoreTranslation(db *gorm.DB, ctx context.Context, id string) (*model.Translation, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	var translation gormModels.Translation
+	if err := transaction.
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at IS NOT NULL").
+		First(&translation, intID).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch deleted translation: %w", err)
+	}
+
+	source, err := restoreLexeme(transaction, translation.SourceLexemeID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+	target, err := restoreLexeme(transaction, translation.TargetLexemeID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := checkTranslationConflict(transaction, translation.ID, source, target); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	// Examples are deleted right after their translation, so the ones removed
+	// earlier on their own stay in the trash.
+	if err := transaction.
+		Unscoped().
+		Model(&gormModels.Example{}).
+		Where("translation_id = ? AND deleted_at >= ?", translation.ID, translation.DeletedAt.Time).
+		Update("deleted_at", nil).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to restore examples: %w", err)
+	}
+
+	translation.SourceLexemeID = source.ID
+	translation.TargetLexemeID = target.ID
+	translation.DeletedAt = gorm.DeletedAt{}
+	translation.UpdatedAt = time.Now()
+	if err := transaction.Unscoped().Omit(clause.Associations).Save(&translation).Error; err != nil {
+		transaction.Rollback()
+		if isUniqueViolation(err) {
+			return nil, translationConflict(source, target)
+		}
+		return nil, fmt.Errorf("failed to restore translation: %w", err)
+	}
+
+	if err := recordRevision(transaction, revisionRestore, translation.ID, nil); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+	publish(db, events.TranslationCreated, translation.ID)
+
+	return findTranslation(db.WithContext(ctx), translation.ID)
+}
+
+// PurgeTrash permanently removes the translations, examples and lexemes
+// deleted before the given time and returns the number of purged
+// translations. Reviews and word list items of the purged translations go
+// with them.
+func PurgeTrash(db *gorm.DB, ctx context.Context, deletedBefore time.Time) (int64, error) {
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return 0, transaction.Error
+	}
+
+	result := transaction.
+		Unscoped().
+		Where("deleted_at < ?", deletedBefore).
+		Delete(&gormModels.Translation{})
+	if result.Error != nil {
+		transaction.Rollback()
+		return 0, fmt.Errorf("failed to purge translations: %w", result.Error)
+	}
+
+	if err := transaction.
+		Unscoped().
+		Where("deleted_at < ?", deletedBefore).
+		Delete(&gormModels.Example{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, fmt.Errorf("failed to purge examples: %w", err)
+	}
+
+	// Lexemes are kept as long as a translation in the trash refers to them.
+	if err := transaction.
+		Unscoped().
+		Where("deleted_at < ?", deletedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM translations WHERE translations.source_lexeme_id = lexemes.id OR translations.target_lexeme_id = lexemes.id)").
+		Delete(&gormModels.Lexeme{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, fmt.Errorf("failed to purge words: %w", err)
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// deleteTranslation moves a translation and its examples to the trash. Its
+// word list items are kept, so that it is back in its lists once restored,
+// and go with it when it is purged.
+func deleteTranslation(transaction *gorm.DB, translation gormModels.Translation) error {
+	if err := transaction.Delete(&translation).Error; err != nil {
+		return fmt.Errorf("failed to delete translation: %w", err)
+	}
+
+	if err := transaction.
+		Where("translation_id = ?", translation.ID).
+		Delete(&gormModels.Example{}).Error; err != nil {
+		return fmt.Errorf("failed to delete examples: %w", err)
+	}
+
+	return nil
+}
+
+// restoreLexeme returns the lexeme with the given id, taking it out of the
+// trash if needed. If another lexeme with the same spelling has been added
+// since it was removed, that one is returned instead.
+func restoreLexeme(transaction *gorm.DB, id uint) (gormModels.Lexeme, error) {
+	var lexeme gormModels.Lexeme
+	if err := transaction.
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&lexeme, id).Error; err != nil {
+		return lexeme, fmt.Errorf("failed to fetch word: %w", err)
+	}
+	if !lexeme.DeletedAt.Valid {
+		return lexeme, nil
+	}
+
+	var existing gormModels.Lexeme
+	if err := transaction.
+		Where("language_code = ? AND word = ?", lexeme.LanguageCode, lexeme.Word).
+		First(&existing).Error; err == nil {
+		return existing, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return lexeme, fmt.Errorf("error checking for existing word: %w", err)
+	}
+
+	if err := transaction.
+		Unscoped().
+		Model(&lexeme).
+		Update("deleted_at", nil).Error; err != nil {
+		return lexeme, fmt.Errorf("failed to restore word: %w", err)
+	}
+	lexeme.DeletedAt = gorm.DeletedAt{}
+
+	return lexeme, nil
+}
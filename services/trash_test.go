@@ -0,0 +1,133 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreTranslation(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	translation, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "zamek",
+		EnglishWord: "castle",
+		Examples: []*model.NewExampleInput{
+			{Sentence: "Zamek stoi na wzgórzu."},
+			{Sentence: "Zwiedziliśmy zamek."},
+		},
+	})
+	_, err := services.RemoveExample(testDB, ctx, expand(t, translation).Examples[1].ID)
+	assert.NoError(t, err, "RemoveExample should not return an error")
+
+	removed, err := services.RemoveTranslation(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+	assert.True(t, removed, "Translation should be removed")
+
+	_, err = services.Translation(testDB, ctx, translation.ID)
+	assert.Error(t, err, "Removed translation should not be found")
+	_, err = services.LexemeByWord(testDB, ctx, "pl", "zamek")
+	assert.Error(t, err, "Lexeme left without translations should not be found")
+
+	deleted, err := services.DeletedTranslations(testDB, ctx, nil)
+	assert.NoError(t, err, "DeletedTranslations should not return an error")
+	if assert.Equal(t, 1, len(deleted.Edges), "Removed translation should be in the trash") {
+		node := deleted.Edges[0].Node
+		assert.Equal(t, translation.ID, node.ID, "Trash should list the removed translation")
+		assert.NotNil(t, node.DeletedAt, "Removed translation should have a deletion time")
+		expanded := expand(t, node)
+		assert.Equal(t, "zamek", expanded.Source.Word, "Removed lexemes should be loaded")
+		assert.Equal(t, 1, len(expanded.Examples), "Only examples removed with the translation should be listed")
+	}
+
+	restored, err := services.RestoreTranslation(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "RestoreTranslation should not return an error")
+	assert.Nil(t, restored.DeletedAt, "Restored translation should not have a deletion time")
+	expanded := expand(t, restored)
+	assert.Equal(t, "zamek", expanded.Source.Word, "Source should be restored")
+	if assert.Equal(t, 1, len(expanded.Examples), "Only examples removed with the translation should be restored") {
+		assert.Equal(t, "Zamek stoi na wzgórzu.", expanded.Examples[0].Sentence, "Example should be restored")
+	}
+
+	_, err = services.LexemeByWord(testDB, ctx, "pl", "zamek")
+	assert.NoError(t, err, "Lexeme should be restored")
+
+	_, err = services.RestoreTranslation(testDB, ctx, translation.ID)
+	assert.Error(t, err, "Translation that is not in the trash cannot be restored")
+
+	history, _ := services.TranslationHistory(testDB, ctx, translation.ID)
+	assert.Equal(t, model.RevisionActionRestore, history[0].Action, "Restore should be recorded")
+}
+
+func TestRestoreTranslationConflict(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	translation, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "castle"})
+	_, err := services.RemoveTranslation(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+
+	added, err := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "castle"})
+	assert.NoError(t, err, "Removed translation should not block adding it again")
+	assert.NotEqual(t, expand(t, translation).Source.ID, expand(t, added).Source.ID, "Removed lexeme should not be reused")
+
+	_, err = services.RestoreTranslation(testDB, ctx, translation.ID)
+	assert.ErrorIs(t, err, services.ErrConflict, "Restoring a translation that was added again should conflict")
+
+	_, err = services.RemoveTranslation(testDB, ctx, added.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+	_, err = services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "zamek", EnglishWord: "lock"})
+	assert.NoError(t, err, "CreateTranslation should not return an error")
+
+	restored, err := services.RestoreTranslation(testDB, ctx, translation.ID)
+	assert.NoError(t, err, "RestoreTranslation should not return an error")
+	lexeme, _ := services.LexemeByWord(testDB, ctx, "pl", "zamek")
+	assert.Equal(t, lexeme.ID, expand(t, restored).Source.ID, "Lexeme added in the meantime should be used")
+}
+
+func TestPurgeTrash(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	old, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{
+		PolishWord:  "zamek",
+		EnglishWord: "castle",
+		Examples:    []*model.NewExampleInput{{Sentence: "Zamek stoi na wzgórzu."}},
+	})
+	_, err := services.RemoveTranslation(testDB, ctx, old.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+
+	cutoff := time.Now()
+
+	recent, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	_, err = services.RemoveTranslation(testDB, ctx, recent.ID)
+	assert.NoError(t, err, "RemoveTranslation should not return an error")
+	kept, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pić", EnglishWord: "drink"})
+
+	purged, err := services.PurgeTrash(testDB, ctx, cutoff)
+	assert.NoError(t, err, "PurgeTrash should not return an error")
+	assert.Equal(t, int64(1), purged, "Only translations deleted before the cutoff should be purged")
+
+	deleted, _ := services.DeletedTranslations(testDB, ctx, nil)
+	if assert.Equal(t, 1, len(deleted.Edges), "Recently removed translation should stay in the trash") {
+		assert.Equal(t, recent.ID, deleted.Edges[0].Node.ID, "Recently removed translation should stay in the trash")
+	}
+
+	_, err = services.RestoreTranslation(testDB, ctx, old.ID)
+	assert.Error(t, err, "Purged translation cannot be restored")
+
+	var remainingLexemes int64
+	testDB.Unscoped().Table("lexemes").Where("word IN ?", []string{"zamek", "castle"}).Count(&remainingLexemes)
+	assert.Equal(t, int64(0), remainingLexemes, "Lexemes of purged translations should be purged")
+
+	_, err = services.Translation(testDB, ctx, kept.ID)
+	assert.NoError(t, err, "Translations that were not removed should be kept")
+}
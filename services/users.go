@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const minPasswordLength = 8
+
+// errInvalidCredentials is returned for both unknown emails and wrong
+// passwords, so that callers cannot probe which emails are registered.
+var errInvalidCredentials = fmt.Errorf("%w: invalid email or password", auth.ErrUnauthenticated)
+
+func Register(db *gorm.DB, ctx context.Context, email string, password string) (*model.User, error) {
+	email = normalizeEmail(email)
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, fmt.Errorf("invalid email '%s'", email)
+	}
+	if len(password) < minPasswordLength {
+		return nil, fmt.Errorf("password must be at least %d characters long", minPasswordLength)
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users always start out as viewers. The first admin is appointed with the
+	// set-role command, so that nobody can claim the role by registering first.
+	user := gormModels.User{Email: email, PasswordHash: hash, Role: auth.RoleViewer}
+	if err := db.WithContext(ctx).Create(&user).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: email '%s' is already registered", ErrConflict, email)
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return ConvertUser(&user), nil
+}
+
+func Login(db *gorm.DB, ctx context.Context, email string, password string) (*model.User, error) {
+	var user gormModels.User
+	if err := db.WithContext(ctx).
+		Where("email = ?", normalizeEmail(email)).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		return nil, errInvalidCredentials
+	}
+
+	return ConvertUser(&user), nil
+}
+
+// SetUserRole changes the role of a user. The last admin cannot be demoted,
+// so that someone is always left to manage roles.
+func SetUserRole(db *gorm.DB, ctx context.Context, id string, role model.Role) (*model.User, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	newRole := strings.ToLower(role.String())
+	if !auth.IsRole(newRole) {
+		return nil, fmt.Errorf("unknown role %s", role)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	// Locking every admin keeps two admins from demoting each other at once.
+	var admins []gormModels.User
+	if err := transaction.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ?", auth.RoleAdmin).
+		Find(&admins).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch admins: %w", err)
+	}
+
+	var user gormModels.User
+	if err := transaction.First(&user, intID).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if user.Role == auth.RoleAdmin && newRole != auth.RoleAdmin && len(admins) == 1 {
+		transaction.Rollback()
+		return nil, fmt.Errorf("cannot demote the last admin")
+	}
+
+	user.Role = newRole
+	if err := transaction.Save(&user).Error; err != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return ConvertUser(&user), nil
+}
+
+// SetUserRoleByEmail changes the role of the user registered with email, like
+// SetUserRole.
+func SetUserRoleByEmail(db *gorm.DB, ctx context.Context, email string, role model.Role) (*model.User, error) {
+	email = normalizeEmail(email)
+
+	var user gormModels.User
+	if err := db.WithContext(ctx).
+		Where("email = ?", email).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("no user registered with email '%s'", email)
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return SetUserRole(db, ctx, strconv.Itoa(int(user.ID)), role)
+}
+
+// ConvertUser converts the authenticated user stored in the request context.
+func ConvertUser(user *gormModels.User) *model.User {
+	return &model.User{
+		ID:        strconv.Itoa(int(user.ID)),
+		Email:     user.Email,
+		Role:      model.Role(strings.ToUpper(user.Role)),
+		CreatedAt: user.CreatedAt.String(),
+	}
+}
+
+// convertUserProfile leaves out the email, for users shown to others.
+func convertUserProfile(user gormModels.User) *model.UserProfile {
+	return &model.UserProfile{
+		ID:        strconv.Itoa(int(user.ID)),
+		CreatedAt: user.CreatedAt.String(),
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	user, err := services.Register(testDB, ctx, " Alice@Example.com ", "password")
+	assert.NoError(t, err, "Register should not return an error")
+	assert.Equal(t, "alice@example.com", user.Email, "Email should be normalized")
+
+	_, err = services.Register(testDB, ctx, "alice@example.com", "password")
+	assert.ErrorIs(t, err, services.ErrConflict, "Duplicate email should conflict")
+
+	_, err = services.Register(testDB, ctx, "bob@example.com", "short")
+	assert.Error(t, err, "Short password should return an error")
+
+	_, err = services.Register(testDB, ctx, "not an email", "password")
+	assert.Error(t, err, "Invalid email should return an error")
+
+	loggedIn, err := services.Login(testDB, ctx, "ALICE@example.com", "password")
+	assert.NoError(t, err, "Login should not return an error")
+	assert.Equal(t, user.ID, loggedIn.ID, "User should match")
+
+	_, err = services.Login(testDB, ctx, "alice@example.com", "wrong password")
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated, "Wrong password should be rejected")
+
+	_, err = services.Login(testDB, ctx, "bob@example.com", "password")
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated, "Unknown email should be rejected")
+}
+
+func TestSetUserRole(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	admin, _ := services.Register(testDB, ctx, "admin@example.com", "password")
+	assert.Equal(t, model.RoleViewer, admin.Role, "First user should be a viewer")
+
+	admin, err := services.SetUserRoleByEmail(testDB, ctx, " Admin@example.com", model.RoleAdmin)
+	assert.NoError(t, err, "SetUserRoleByEmail should not return an error")
+	assert.Equal(t, model.RoleAdmin, admin.Role, "Role should be updated")
+
+	_, err = services.SetUserRoleByEmail(testDB, ctx, "nobody@example.com", model.RoleAdmin)
+	assert.Error(t, err, "Unknown email should return an error")
+
+	user, _ := services.Register(testDB, ctx, "user@example.com", "password")
+	assert.Equal(t, model.RoleViewer, user.Role, "Other users should be viewers")
+
+	user, err = services.SetUserRole(testDB, ctx, user.ID, model.RoleEditor)
+	assert.NoError(t, err, "SetUserRole should not return an error")
+	assert.Equal(t, model.RoleEditor, user.Role, "Role should be updated")
+
+	_, err = services.SetUserRole(testDB, ctx, admin.ID, model.RoleViewer)
+	assert.Error(t, err, "Last admin should not be demoted")
+
+	services.SetUserRole(testDB, ctx, user.ID, model.RoleAdmin)
+	admin, err = services.SetUserRole(testDB, ctx, admin.ID, model.RoleViewer)
+	assert.NoError(t, err, "Admin should be demoted once there is another one")
+	assert.Equal(t, model.RoleViewer, admin.Role, "Role should be updated")
+}
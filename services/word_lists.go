@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	gormModels "github.com/pgrzankowski/dictionary-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func MyWordLists(db *gorm.DB, ctx context.Context, userID uint) ([]*model.WordList, error) {
+	return findWordLists(db.WithContext(ctx), "owner_id = ?", userID)
+}
+
+// WordList returns a public list, or a private one if it belongs to userID.
+// Private lists of other users are reported as not found.
+func WordList(db *gorm.DB, ctx context.Context, userID uint, id string) (*model.WordList, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	lists, err := findWordLists(db.WithContext(ctx), "id = ? AND (visibility = ? OR owner_id = ?)",
+		intID, wordListVisibility(model.WordListVisibilityPublic), userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return lists[0], nil
+}
+
+func CreateWordList(db *gorm.DB, ctx context.Context, userID uint, input model.NewWordListInput) (*model.WordList, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("word list name cannot be empty")
+	}
+
+	visibility := model.WordListVisibilityPrivate
+	if input.Visibility != nil {
+		visibility = *input.Visibility
+	}
+
+	translationIDs, err := parseTranslationIDs(input.TranslationIds)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	if err := checkTranslationsExist(transaction, translationIDs); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	list := gormModels.WordList{
+		OwnerID:    userID,
+		Name:       name,
+		Visibility: wordListVisibility(visibility),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := transaction.Omit(clause.Associations).Create(&list).Error; err != nil {
+		transaction.Rollback()
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: word list '%s' already exists", ErrConflict, name)
+		}
+		return nil, fmt.Errorf("failed to create word list: %w", err)
+	}
+
+	for position, translationID := range translationIDs {
+		item := gormModels.WordListItem{
+			WordListID:    list.ID,
+			TranslationID: translationID,
+			Position:      position,
+			CreatedAt:     time.Now(),
+		}
+		if err := transaction.Omit(clause.Associations).Create(&item).Error; err != nil {
+			transaction.Rollback()
+			return nil, fmt.Errorf("failed to add translation %d: %w", translationID, err)
+		}
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return findWordList(db.WithContext(ctx), list.ID)
+}
+
+func UpdateWordList(db *gorm.DB, ctx context.Context, userID uint, input model.UpdateWordListInput) (*model.WordList, error) {
+	intID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	list, err := lockWordList(transaction, uint(intID), userID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			transaction.Rollback()
+			return nil, fmt.Errorf("word list name cannot be empty")
+		}
+		list.Name = name
+	}
+	if input.Visibility != nil {
+		list.Visibility = wordListVisibility(*input.Visibility)
+	}
+
+	list.UpdatedAt = time.Now()
+	if err := transaction.Omit(clause.Associations).Save(&list).Error; err != nil {
+		transaction.Rollback()
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: word list '%s' already exists", ErrConflict, list.Name)
+		}
+		return nil, fmt.Errorf("failed to update word list: %w", err)
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return findWordList(db.WithContext(ctx), list.ID)
+}
+
+func DeleteWordList(db *gorm.DB, ctx context.Context, userID uint, id string) (bool, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid id format: %w", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return false, transaction.Error
+	}
+
+	list, err := lockWordList(transaction, uint(intID), userID)
+	if err != nil {
+		transaction.Rollback()
+		return false, err
+	}
+
+	if err := transaction.Delete(&list).Error; err != nil {
+		transaction.Rollback()
+		return false, fmt.Errorf("failed to delete word list: %w", err)
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// AddToWordList inserts a translation at position, or appends it when position
+// is nil.
+func AddToWordList(db *gorm.DB, ctx context.Context, userID uint, listID string, translationID string, position *int32) (*model.WordList, error) {
+	intListID, err := strconv.Atoi(listID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+	translationIDs, err := parseTranslationIDs([]string{translationID})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	list, err := lockWordList(transaction, uint(intListID), userID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := checkTranslationsExist(transaction, translationIDs); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	items, err := wordListItems(transaction, list.ID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	index := len(items)
+	if position != nil {
+		if *position < 0 {
+			transaction.Rollback()
+			return nil, fmt.Errorf("position cannot be negative")
+		}
+		index = min(int(*position), len(items))
+	}
+
+	// Positions only need to keep the items in order, so the items from
+	// index on are moved back to make room.
+	newPosition := 0
+	if index < len(items) {
+		newPosition = items[index].Position
+		if err := transaction.Model(&gormModels.WordListItem{}).
+			Where("word_list_id = ? AND position >= ?", list.ID, newPosition).
+			Update("position", gorm.Expr("position + 1")).Error; err != nil {
+			transaction.Rollback()
+			return nil, fmt.Errorf("failed to update positions: %w", err)
+		}
+	} else if len(items) > 0 {
+		newPosition = items[len(items)-1].Position + 1
+	}
+
+	item := gormModels.WordListItem{
+		WordListID:    list.ID,
+		TranslationID: translationIDs[0],
+		Position:      newPosition,
+		CreatedAt:     time.Now(),
+	}
+	if err := transaction.Omit(clause.Associations).Create(&item).Error; err != nil {
+		transaction.Rollback()
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: translation %s is already in the list", ErrConflict, translationID)
+		}
+		return nil, fmt.Errorf("failed to add translation: %w", err)
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return findWordList(db.WithContext(ctx), list.ID)
+}
+
+func RemoveFromWordList(db *gorm.DB, ctx context.Context, userID uint, listID string, translationID string) (*model.WordList, error) {
+	intListID, err := strconv.Atoi(listID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+	intTranslationID, err := strconv.Atoi(translationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	list, err := lockWordList(transaction, uint(intListID), userID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	result := transaction.
+		Where("word_list_id = ? AND translation_id = ?", list.ID, intTranslationID).
+		Delete(&gormModels.WordListItem{})
+	if result.Error != nil {
+		transaction.Rollback()
+		return nil, fmt.Errorf("failed to remove translation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		transaction.Rollback()
+		return nil, fmt.Errorf("translation %s is not in the list", translationID)
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return findWordList(db.WithContext(ctx), list.ID)
+}
+
+// ReorderWordList puts the items of a list in the order given by
+// translationIDs, which must list every translation of the list exactly once.
+func ReorderWordList(db *gorm.DB, ctx context.Context, userID uint, listID string, translationIDs []string) (*model.WordList, error) {
+	intListID, err := strconv.Atoi(listID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: %v", err)
+	}
+
+	transaction := db.WithContext(ctx).Begin()
+	if transaction.Error != nil {
+		return nil, transaction.Error
+	}
+
+	list, err := lockWordList(transaction, uint(intListID), userID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	items, err := wordListItems(transaction, list.ID)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if len(translationIDs) != len(items) {
+		transaction.Rollback()
+		return nil, fmt.Errorf("expected %d translation ids, got %d", len(items), len(translationIDs))
+	}
+
+	byTranslation := make(map[string]gormModels.WordListItem, len(items))
+	for _, item := range items {
+		byTranslation[strconv.Itoa(int(item.TranslationID))] = item
+	}
+
+	for position, id := range translationIDs {
+		item, ok := byTranslation[id]
+		if !ok {
+			transaction.Rollback()
+			return nil, fmt.Errorf("translation %s is not in the list or is listed twice", id)
+		}
+		delete(byTranslation, id)
+
+		if item.Position == position {
+			continue
+		}
+		if err := transaction.Model(&gormModels.WordListItem{}).
+			Where("id = ?", item.ID).
+			Update("position", position).Error; err != nil {
+			transaction.Rollback()
+			return nil, fmt.Errorf("failed to update positions: %w", err)
+		}
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return findWordList(db.WithContext(ctx), list.ID)
+}
+
+// lockWordList locks a list of userID for changes to it or its items.
+func lockWordList(transaction *gorm.DB, id uint, userID uint) (gormModels.WordList, error) {
+	var list gormModels.WordList
+	if err := transaction.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&list, id).Error; err != nil {
+		return list, fmt.Errorf("failed to fetch word list: %w", err)
+	}
+
+	if list.OwnerID != userID {
+		return list, fmt.Errorf("%w: word list %d belongs to another user", auth.ErrForbidden, id)
+	}
+
+	return list, nil
+}
+
+// wordListItems returns the items of a list in order, leaving out those of
+// translations in the trash.
+func wordListItems(transaction *gorm.DB, listID uint) ([]gormModels.WordListItem, error) {
+	var items []gormModels.WordListItem
+	if err := transaction.
+		Where("word_list_id = ?", listID).
+		Where("translation_id IN (SELECT id FROM translations WHERE deleted_at IS NULL)").
+		Scopes(orderWordListItems).
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch word list items: %w", err)
+	}
+	return items, nil
+}
+
+func checkTranslationsExist(transaction *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var count int64
+	if err := transaction.Model(&gormModels.Translation{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to fetch translations: %w", err)
+	}
+	if int(count) != len(ids) {
+		return fmt.Errorf("failed to fetch translations: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func parseTranslationIDs(ids []string) ([]uint, error) {
+	result := make([]uint, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		intID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id format: %v", err)
+		}
+		if seen[intID] {
+			return nil, fmt.Errorf("translation %s is listed twice", id)
+		}
+		seen[intID] = true
+		result = append(result, uint(intID))
+	}
+	return result, nil
+}
+
+func findWordList(db *gorm.DB, id uint) (*model.WordList, error) {
+	lists, err := findWordLists(db, "id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return lists[0], nil
+}
+
+// findWordLists loads the matching lists along with their items, whose
+// translations are fetched in a single query. Items of translations in the
+// trash are left out.
+func findWordLists(db *gorm.DB, query string, args ...interface{}) ([]*model.WordList, error) {
+	var lists []gormModels.WordList
+	if err := db.
+		Preload("Owner").
+		Preload("Items", orderWordListItems).
+		Where(query, args...).
+		Order("name, id").
+		Find(&lists).Error; err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for _, list := range lists {
+		for _, item := range list.Items {
+			ids = append(ids, item.TranslationID)
+		}
+	}
+
+	translations := make(map[uint]*model.Translation, len(ids))
+	if len(ids) > 0 {
+		var found []gormModels.Translation
+		if err := db.Find(&found, ids).Error; err != nil {
+			return nil, err
+		}
+		for _, translation := range found {
+			translations[translation.ID] = convertTranslation(translation)
+		}
+	}
+
+	result := make([]*model.WordList, 0, len(lists))
+	for _, list := range lists {
+		result = append(result, convertWordList(list, translations))
+	}
+	return result, nil
+}
+
+func orderWordListItems(db *gorm.DB) *gorm.DB {
+	return db.Order("word_list_items.position, word_list_items.id")
+}
+
+func wordListVisibility(visibility model.WordListVisibility) string {
+	return strings.ToLower(visibility.String())
+}
+
+// convertWordList numbers the items from zero, the stored positions only
+// keep them in order.
+func convertWordList(list gormModels.WordList, translations map[uint]*model.Translation) *model.WordList {
+	result := &model.WordList{
+		ID:         strconv.Itoa(int(list.ID)),
+		Name:       list.Name,
+		Visibility: model.WordListVisibility(strings.ToUpper(list.Visibility)),
+		Owner:      convertUserProfile(list.Owner),
+		CreatedAt:  list.CreatedAt.String(),
+		UpdatedAt:  list.UpdatedAt.String(),
+		Items:      []*model.WordListItem{},
+	}
+	for _, item := range list.Items {
+		translation, ok := translations[item.TranslationID]
+		if !ok {
+			continue
+		}
+		result.Items = append(result.Items, &model.WordListItem{
+			ID:          strconv.Itoa(int(item.ID)),
+			Position:    int32(len(result.Items)),
+			AddedAt:     item.CreatedAt.String(),
+			Translation: translation,
+		})
+	}
+
+	return result
+}
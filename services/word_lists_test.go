@@ -0,0 +1,102 @@
+package services_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/pgrzankowski/dictionary-app/auth"
+	"github.com/pgrzankowski/dictionary-app/graph/model"
+	"github.com/pgrzankowski/dictionary-app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func registerTestUser(t *testing.T, email string) uint {
+	user, err := services.Register(testDB, context.Background(), email, "password")
+	if err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+
+	id, _ := strconv.Atoi(user.ID)
+	return uint(id)
+}
+
+func TestWordLists(t *testing.T) {
+
+	setupTestDB(t)
+
+	ctx := context.Background()
+	alice := registerTestUser(t, "alice@example.com")
+	bob := registerTestUser(t, "bob@example.com")
+	write, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pisać", EnglishWord: "write"})
+	read, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "czytać", EnglishWord: "read"})
+	drink, _ := services.CreateTranslation(testDB, ctx, model.NewTranslationInput{PolishWord: "pić", EnglishWord: "drink"})
+
+	list, err := services.CreateWordList(testDB, ctx, alice, model.NewWordListInput{
+		Name:           "Week 3 verbs",
+		TranslationIds: []string{write.ID, read.ID},
+	})
+	assert.NoError(t, err, "CreateWordList should not return an error")
+	assert.Equal(t, model.WordListVisibilityPrivate, list.Visibility, "Lists should be private by default")
+	assert.Equal(t, strconv.Itoa(int(alice)), list.Owner.ID, "Owner should be the creator")
+	assert.Equal(t, 2, len(list.Items), "Items should be added")
+
+	_, err = services.CreateWordList(testDB, ctx, alice, model.NewWordListInput{Name: "Week 3 verbs"})
+	assert.ErrorIs(t, err, services.ErrConflict, "List names should be unique per owner")
+
+	_, err = services.CreateWordList(testDB, ctx, bob, model.NewWordListInput{Name: "Week 3 verbs"})
+	assert.NoError(t, err, "Other users should be able to use the same name")
+
+	position := int32(1)
+	list, err = services.AddToWordList(testDB, ctx, alice, list.ID, drink.ID, &position)
+	assert.NoError(t, err, "AddToWordList should not return an error")
+	assert.Equal(t, []string{write.ID, drink.ID, read.ID}, wordListTranslationIDs(list), "Translation should be inserted at position")
+
+	list, err = services.ReorderWordList(testDB, ctx, alice, list.ID, []string{read.ID, write.ID, drink.ID})
+	assert.NoError(t, err, "ReorderWordList should not return an error")
+	assert.Equal(t, []string{read.ID, write.ID, drink.ID}, wordListTranslationIDs(list), "Items should be reordered")
+	assert.Equal(t, int32(2), list.Items[2].Position, "Positions should be consecutive")
+
+	_, err = services.AddToWordList(testDB, ctx, bob, list.ID, drink.ID, nil)
+	assert.ErrorIs(t, err, auth.ErrForbidden, "Other users should not change the list")
+
+	_, err = services.WordList(testDB, ctx, bob, list.ID)
+	assert.Error(t, err, "Private lists should be hidden from other users")
+
+	public := model.WordListVisibilityPublic
+	services.UpdateWordList(testDB, ctx, alice, model.UpdateWordListInput{ID: list.ID, Visibility: &public})
+	_, err = services.WordList(testDB, ctx, bob, list.ID)
+	assert.NoError(t, err, "Public lists should be visible to other users")
+
+	services.RemoveTranslation(testDB, ctx, write.ID)
+	list, _ = services.WordList(testDB, ctx, alice, list.ID)
+	assert.Equal(t, []string{read.ID, drink.ID}, wordListTranslationIDs(list), "Removed translations should leave the list")
+
+	list, err = services.ReorderWordList(testDB, ctx, alice, list.ID, []string{drink.ID, read.ID})
+	assert.NoError(t, err, "Translations in the trash should not have to be reordered")
+	_, err = services.RestoreTranslation(testDB, ctx, write.ID)
+	assert.NoError(t, err, "RestoreTranslation should not return an error")
+	list, _ = services.WordList(testDB, ctx, alice, list.ID)
+	assert.Contains(t, wordListTranslationIDs(list), write.ID, "Restored translations should be back in the list")
+	services.RemoveTranslation(testDB, ctx, write.ID)
+
+	list, err = services.RemoveFromWordList(testDB, ctx, alice, list.ID, read.ID)
+	assert.NoError(t, err, "RemoveFromWordList should not return an error")
+	assert.Equal(t, []string{drink.ID}, wordListTranslationIDs(list), "Translation should be removed")
+
+	lists, err := services.MyWordLists(testDB, ctx, alice)
+	assert.NoError(t, err, "MyWordLists should not return an error")
+	assert.Equal(t, 1, len(lists), "Only own lists should be listed")
+
+	removed, err := services.DeleteWordList(testDB, ctx, alice, list.ID)
+	assert.NoError(t, err, "DeleteWordList should not return an error")
+	assert.True(t, removed, "DeleteWordList should return true")
+}
+
+func wordListTranslationIDs(list *model.WordList) []string {
+	var ids []string
+	for _, item := range list.Items {
+		ids = append(ids, item.Translation.ID)
+	}
+	return ids
+}
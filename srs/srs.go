@@ -0,0 +1,85 @@
+// Package srs implements SM-2 spaced repetition scheduling.
+package srs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// MinGrade and MaxGrade bound the grades of SM-2, where a grade below
+	// PassingGrade means the answer was not recalled.
+	MinGrade     = 0
+	MaxGrade     = 5
+	PassingGrade = 3
+
+	InitialEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+)
+
+// Clock tells the current time, so that scheduling can be tested with a fixed
+// one.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// State is the learning progress of a single item.
+type State struct {
+	EaseFactor float64
+	// Interval is the number of days until the next review.
+	Interval    int
+	Repetitions int
+	Due         time.Time
+}
+
+// NewState returns the state of an item that has never been reviewed, which
+// is due right away.
+func NewState(now time.Time) State {
+	return State{EaseFactor: InitialEaseFactor, Due: now}
+}
+
+// Scheduler applies reviews at the time told by its Clock.
+type Scheduler struct {
+	Clock Clock
+}
+
+func NewScheduler(clock Clock) *Scheduler {
+	return &Scheduler{Clock: clock}
+}
+
+// Review returns the state following a review of the given grade.
+func (s *Scheduler) Review(state State, grade int) (State, error) {
+	if grade < MinGrade || grade > MaxGrade {
+		return state, fmt.Errorf("grade must be between %d and %d", MinGrade, MaxGrade)
+	}
+
+	next := state
+	if grade < PassingGrade {
+		next.Repetitions = 0
+		next.Interval = 1
+	} else {
+		switch next.Repetitions {
+		case 0:
+			next.Interval = 1
+		case 1:
+			next.Interval = 6
+		default:
+			next.Interval = int(math.Round(float64(state.Interval) * state.EaseFactor))
+		}
+		next.Repetitions++
+	}
+
+	missed := float64(MaxGrade - grade)
+	next.EaseFactor = math.Max(MinEaseFactor, state.EaseFactor+0.1-missed*(0.08+missed*0.02))
+	next.Due = s.Clock.Now().AddDate(0, 0, next.Interval)
+
+	return next, nil
+}
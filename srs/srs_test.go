@@ -0,0 +1,68 @@
+package srs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pgrzankowski/dictionary-app/srs"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+var now = time.Date(2025, 2, 20, 12, 0, 0, 0, time.UTC)
+
+func TestReviewIntervals(t *testing.T) {
+	scheduler := srs.NewScheduler(fixedClock(now))
+	state := srs.NewState(now)
+
+	expected := []int{1, 6, 15, 38}
+	for i, interval := range expected {
+		var err error
+		state, err = scheduler.Review(state, 4)
+		assert.NoError(t, err, "Review should not return an error")
+		assert.Equal(t, interval, state.Interval, "Interval after review %d should match", i+1)
+		assert.Equal(t, i+1, state.Repetitions, "Repetitions should be counted")
+	}
+	assert.Equal(t, now.AddDate(0, 0, 38), state.Due, "Due date should follow the interval")
+	assert.InDelta(t, 2.5, state.EaseFactor, 1e-9, "Grade 4 should keep the ease factor")
+}
+
+func TestReviewEaseFactor(t *testing.T) {
+	scheduler := srs.NewScheduler(fixedClock(now))
+
+	state, _ := scheduler.Review(srs.NewState(now), 5)
+	assert.InDelta(t, 2.6, state.EaseFactor, 1e-9, "Perfect grade should raise the ease factor")
+
+	state, _ = scheduler.Review(srs.NewState(now), 3)
+	assert.InDelta(t, 2.36, state.EaseFactor, 1e-9, "Hard grade should lower the ease factor")
+
+	state = srs.State{EaseFactor: 1.4, Interval: 10, Repetitions: 3}
+	state, _ = scheduler.Review(state, 0)
+	assert.Equal(t, srs.MinEaseFactor, state.EaseFactor, "Ease factor should not drop below the minimum")
+}
+
+func TestReviewLapse(t *testing.T) {
+	scheduler := srs.NewScheduler(fixedClock(now))
+
+	state := srs.State{EaseFactor: 2.5, Interval: 15, Repetitions: 3, Due: now}
+	state, err := scheduler.Review(state, 2)
+	assert.NoError(t, err, "Review should not return an error")
+	assert.Equal(t, 0, state.Repetitions, "Failed review should restart repetitions")
+	assert.Equal(t, 1, state.Interval, "Failed review should be repeated the next day")
+	assert.Equal(t, now.AddDate(0, 0, 1), state.Due, "Due date should follow the interval")
+}
+
+func TestReviewInvalidGrade(t *testing.T) {
+	scheduler := srs.NewScheduler(fixedClock(now))
+
+	_, err := scheduler.Review(srs.NewState(now), 6)
+	assert.Error(t, err, "Grade above the maximum should return an error")
+
+	_, err = scheduler.Review(srs.NewState(now), -1)
+	assert.Error(t, err, "Grade below the minimum should return an error")
+}
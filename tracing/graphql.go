@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Extension records a span for every GraphQL operation, with the parsing and
+// validation of its document and the fields it resolves with a resolver as
+// children. Fields read from the objects the resolvers return are not
+// recorded. A subscription is a single span lasting until it ends.
+type Extension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.FieldInterceptor
+} = Extension{}
+
+func (Extension) ExtensionName() string {
+	return "Tracing"
+}
+
+func (Extension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	operation := graphql.GetOperationContext(ctx)
+	kind, name := string(operation.Operation.Operation), operation.Operation.Name
+	spanName := kind
+	if name != "" {
+		spanName += " " + name
+	}
+
+	start := operation.Stats.OperationStart
+	if start.IsZero() {
+		start = time.Now()
+	}
+	ctx, span := tracer().Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			attribute.String("graphql.operation.type", kind),
+			attribute.String("graphql.operation.name", name),
+		),
+	)
+	phase(ctx, "graphql.parse", operation.Stats.Parsing)
+	phase(ctx, "graphql.validate", operation.Stats.Validation)
+
+	var end sync.Once
+	responses := next(ctx)
+	return func(ctx context.Context) *graphql.Response {
+		response := responses(ctx)
+		if response != nil && len(response.Errors) > 0 {
+			span.SetStatus(codes.Error, response.Errors.Error())
+		}
+		if response == nil || operation.Operation.Operation != ast.Subscription {
+			end.Do(func() { span.End() })
+		}
+
+		return response
+	}
+}
+
+// phase records a finished step of the operation in ctx as a span.
+func phase(ctx context.Context, name string, timing graphql.TraceTiming) {
+	if timing.Start.IsZero() {
+		return
+	}
+	_, span := tracer().Start(ctx, name, trace.WithTimestamp(timing.Start))
+	span.End(trace.WithTimestamp(timing.End))
+}
+
+func (Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	field := graphql.GetFieldContext(ctx)
+	if field == nil || !field.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := tracer().Start(ctx, field.Object+"."+field.Field.Name, trace.WithAttributes(
+		attribute.String("graphql.field.path", field.Path().String()),
+	))
+	defer span.End()
+
+	result, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return result, err
+}
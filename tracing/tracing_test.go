@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordSpans makes the spans of the test go to the returned exporter.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return exporter
+}
+
+func spanNamed(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func attributeOf(span *tracetest.SpanStub, key attribute.Key) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+var testSchema = gqlparser.MustLoadSchema(&ast.Source{Input: `
+	type Query {
+		translation: String
+		word: String
+	}
+`})
+
+// resolve runs a field of Query the way generated code does, through the
+// field interceptors.
+func resolve(ctx context.Context, name string, isResolver bool, resolver graphql.Resolver) (any, error) {
+	ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{
+		Object:     "Query",
+		IsResolver: isResolver,
+		Field: graphql.CollectedField{Field: &ast.Field{
+			Name:       name,
+			Alias:      name,
+			Definition: testSchema.Query.Fields.ForName(name),
+		}},
+	})
+	return graphql.GetOperationContext(ctx).ResolverMiddleware(ctx, resolver)
+}
+
+func newTestServer() http.Handler {
+	srv := handler.New(&graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema { return testSchema },
+		ComplexityFunc: func(typeName string, fieldName string, childComplexity int, args map[string]any) (int, bool) {
+			return 1, true
+		},
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			return graphql.OneShot(func() *graphql.Response {
+				if _, err := resolve(ctx, "translation", true, func(ctx context.Context) (any, error) {
+					return nil, errors.New("translation not found")
+				}); err != nil {
+					graphql.AddError(ctx, err)
+				}
+				resolve(ctx, "word", false, func(ctx context.Context) (any, error) {
+					return "zamek", nil
+				})
+				return &graphql.Response{Data: []byte(`{"translation":null,"word":"zamek"}`), Errors: graphql.GetErrors(ctx)}
+			}())
+		},
+	})
+	srv.AddTransport(transport.POST{})
+	srv.Use(Extension{})
+
+	return Middleware(srv)
+}
+
+func TestExtension(t *testing.T) {
+	exporter := recordSpans(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query":"query Lookup { translation word }"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	newTestServer().ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := exporter.GetSpans()
+	operation := spanNamed(spans, "query Lookup")
+	if !assert.NotNil(t, operation, "Operation should be traced") {
+		return
+	}
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", operation.SpanContext.TraceID().String(), "Trace of the caller should be continued")
+	assert.Equal(t, "00f067aa0ba902b7", operation.Parent.SpanID().String(), "Span of the caller should be the parent")
+	assert.Equal(t, trace.SpanKindServer, operation.SpanKind, "Operation should be a server span")
+	assert.Equal(t, "Lookup", attributeOf(operation, "graphql.operation.name"), "Operation name should be recorded")
+	assert.Equal(t, codes.Error, operation.Status.Code, "Operation with errors should fail")
+
+	for _, name := range []string{"graphql.parse", "graphql.validate", "Query.translation"} {
+		span := spanNamed(spans, name)
+		if assert.NotNil(t, span, "%s should be traced", name) {
+			assert.Equal(t, operation.SpanContext.SpanID(), span.Parent.SpanID(), "%s should be a child of the operation", name)
+		}
+	}
+	field := spanNamed(spans, "Query.translation")
+	if assert.NotNil(t, field) {
+		assert.Equal(t, codes.Error, field.Status.Code, "Failing resolver should fail")
+		assert.Equal(t, "translation", attributeOf(field, "graphql.field.path"), "Field path should be recorded")
+	}
+	assert.Nil(t, spanNamed(spans, "Query.word"), "Fields without a resolver should not be traced")
+}
+
+type word struct {
+	ID   uint
+	Word string
+}
+
+func TestGORMPlugin(t *testing.T) {
+	exporter := recordSpans(t)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost user=test dbname=test"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	assert.NoError(t, err, "gorm.Open should not return an error")
+	assert.NoError(t, db.Use(GORMPlugin{}), "Use should not return an error")
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	var words []word
+	db.WithContext(ctx).Where("word = ?", "zamek").Find(&words)
+	db.WithContext(ctx).Create(&word{Word: "sekret"})
+	parent.End()
+
+	query := spanNamed(exporter.GetSpans(), "gorm.query")
+	if assert.NotNil(t, query, "Query should be traced") {
+		assert.Equal(t, parent.SpanContext().SpanID(), query.Parent.SpanID(), "Span in the context should be the parent")
+		assert.Equal(t, `SELECT * FROM "words" WHERE word = $1`, attributeOf(query, "db.statement"), "Statement should be recorded with placeholders")
+		assert.Equal(t, "words", attributeOf(query, "db.sql.table"), "Table should be recorded")
+		assert.Equal(t, trace.SpanKindClient, query.SpanKind, "Statement should be a client span")
+	}
+	create := spanNamed(exporter.GetSpans(), "gorm.create")
+	if assert.NotNil(t, create, "Insert should be traced") {
+		assert.NotContains(t, attributeOf(create, "db.statement"), "sekret", "Values should not be recorded")
+	}
+}